@@ -0,0 +1,73 @@
+package gvm
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "管理版本别名",
+	Long: `为已安装的版本命名，之后可以在 use、shell 等命令中使用别名。
+
+别名保存在 ~/.gvm/aliases.json。
+
+示例:
+  gvm alias set prod 1.22.5   # 设置别名
+  gvm use prod                # 使用别名切换版本
+  gvm alias list              # 列出所有别名
+  gvm alias rm prod           # 删除别名`,
+}
+
+var aliasSetCmd = &cobra.Command{
+	Use:   "set <name> <version>",
+	Short: "设置别名",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		v, err := manager().SetAlias(cmd.Context(), args[0], args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s -> go%s\n", args[0], v)
+		return nil
+	},
+}
+
+var aliasListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "列出所有别名",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		aliases, err := manager().Aliases(cmd.Context())
+		if err != nil {
+			return err
+		}
+		names := make([]string, 0, len(aliases))
+		for name := range aliases {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Printf("%s -> go%s\n", name, aliases[name])
+		}
+		return nil
+	},
+}
+
+var aliasRmCmd = &cobra.Command{
+	Use:     "rm <name>",
+	Aliases: []string{"remove", "unset"},
+	Short:   "删除别名",
+	Args:    cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return manager().RemoveAlias(cmd.Context(), args[0])
+	},
+}
+
+func init() {
+	aliasCmd.AddCommand(aliasSetCmd, aliasListCmd, aliasRmCmd)
+	rootCmd.AddCommand(aliasCmd)
+}
@@ -0,0 +1,90 @@
+package gvm
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	gvmlib "github.com/ibreez3/gvm/pkg/gvm"
+	"github.com/spf13/cobra"
+)
+
+var cacheOlderThan string
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "管理下载缓存",
+	Long: `管理 Go 安装包下载缓存。
+
+安装包按 SHA-256 存放在缓存目录的 archives/<sha256>/ 下，重新安装时如果校验和一致则直接复用。
+缓存目录默认为 ~/.gvm/cache，可通过 gvm config --cache-dir 修改 (例如指向多台机器共享的目录)。
+
+示例:
+  gvm cache list                    # 列出缓存的安装包
+  gvm cache size                    # 查看缓存占用
+  gvm cache clean                   # 清空缓存
+  gvm cache clean --older-than 30d  # 清理 30 天内未使用的安装包`,
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "列出缓存的安装包",
+	Args:    cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		archives, err := manager().CachedArchives(cmd.Context())
+		if err != nil {
+			return err
+		}
+		for _, a := range archives {
+			fmt.Printf("%-40s %10s  %s  %s\n", a.Filename, strings.TrimSpace(gvmlib.FormatSize(a.Size)), a.LastUsed.Format("2006-01-02 15:04"), a.SHA256[:12])
+		}
+		return nil
+	},
+}
+
+var cacheSizeCmd = &cobra.Command{
+	Use:   "size",
+	Short: "查看缓存占用",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		m := manager()
+		size, err := m.CacheSize(cmd.Context())
+		if err != nil {
+			return err
+		}
+		dir, _ := m.CacheDir(cmd.Context())
+		fmt.Printf("%s\t%s\n", strings.TrimSpace(gvmlib.FormatSize(size)), dir)
+		return nil
+	},
+}
+
+var cacheCleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "清理缓存",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var age time.Duration
+		if cacheOlderThan != "" {
+			var err error
+			if age, err = gvmlib.ParseAge(cacheOlderThan); err != nil {
+				return err
+			}
+			if age == 0 {
+				return fmt.Errorf("--older-than 必须大于 0")
+			}
+		}
+		freed, err := manager().CleanCache(cmd.Context(), age)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("已释放 %s\n", strings.TrimSpace(gvmlib.FormatSize(freed)))
+		return nil
+	},
+}
+
+func init() {
+	cacheCleanCmd.Flags().StringVar(&cacheOlderThan, "older-than", "", "只清理超过指定时间未使用的安装包 (如 30d, 12h)")
+	cacheCmd.AddCommand(cacheListCmd, cacheSizeCmd, cacheCleanCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
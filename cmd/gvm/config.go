@@ -1,16 +1,26 @@
 package gvm
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"path/filepath"
+	"strconv"
 
-	"github.com/ibreez3/gvm/internal/core"
+	gvmlib "github.com/ibreez3/gvm/pkg/gvm"
 	"github.com/spf13/cobra"
 )
 
 var (
 	configSource      string
 	configSourceJSON  string
+	configCacheDir    string
+	configHTTPProxy   string
+	configHTTPCAFile  string
+	configHTTPCert    string
+	configHTTPKey     string
+	configHTTPTimeout string
+	configHTTPRetries string
 	configShow        bool
 	configReset       bool
 )
@@ -24,42 +34,57 @@ var configCmd = &cobra.Command{
 
 可用配置项:
   download_source      Go 版本下载源 (默认: https://go.dev/dl/)
-  download_source_json Go 版本 JSON API (默认: https://go.dev/dl/?mode=json&include=all)`,
+  download_source_json Go 版本 JSON API (默认: https://go.dev/dl/?mode=json&include=all)
+  cache_dir            下载缓存目录，可指向多台机器共享的目录 (默认: ~/.gvm/cache)
+  http.proxy           HTTP(S) 代理 URL (默认: 使用 HTTPS_PROXY/HTTP_PROXY 环境变量)
+  http.ca_file         额外信任的 CA 证书 (PEM)，用于自签名证书的内部镜像
+  http.client_cert     mTLS 客户端证书 (PEM)
+  http.client_key      mTLS 客户端私钥 (PEM，默认与 client_cert 同一文件)
+  http.timeout         连接、等待响应头和下载无数据的超时 (默认: 30s，不限制下载总时长)
+  http.retries         请求失败 (5xx/429/连接被重置) 后的重试次数，指数退避 (默认: 3)
+
+http.* 同时用于版本索引、安装包下载和 self-update。`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return handleConfigCommand()
+		return handleConfigCommand(cmd.Context())
 	},
 }
 
 func init() {
 	configCmd.Flags().StringVar(&configSource, "source", "", "设置 Go 下载源 URL")
 	configCmd.Flags().StringVar(&configSourceJSON, "json-source", "", "设置 Go JSON API URL")
+	configCmd.Flags().StringVar(&configCacheDir, "cache-dir", "", "设置下载缓存目录 (\"default\" 恢复默认)")
+	configCmd.Flags().StringVar(&configHTTPProxy, "http-proxy", "", "设置 HTTP(S) 代理 URL (\"default\" 恢复默认)")
+	configCmd.Flags().StringVar(&configHTTPCAFile, "http-ca-file", "", "设置额外信任的 CA 证书文件 (PEM) (\"default\" 恢复默认)")
+	configCmd.Flags().StringVar(&configHTTPCert, "http-client-cert", "", "设置 mTLS 客户端证书文件 (PEM) (\"default\" 恢复默认)")
+	configCmd.Flags().StringVar(&configHTTPKey, "http-client-key", "", "设置 mTLS 客户端私钥文件 (PEM) (\"default\" 恢复默认)")
+	configCmd.Flags().StringVar(&configHTTPTimeout, "http-timeout", "", "设置连接和等待数据的超时，如 30s (\"default\" 恢复默认)")
+	configCmd.Flags().StringVar(&configHTTPRetries, "http-retries", "", "设置请求失败后的重试次数 (\"default\" 恢复默认)")
 	configCmd.Flags().BoolVar(&configShow, "show", false, "显示当前配置")
 	configCmd.Flags().BoolVar(&configReset, "reset", false, "重置为默认配置")
 	rootCmd.AddCommand(configCmd)
 }
 
-func handleConfigCommand() error {
+func handleConfigCommand(ctx context.Context) error {
 	// Load current config
-	cfg, err := core.LoadConfig()
+	m := manager()
+	cfg, err := m.Config(ctx)
 	if err != nil {
 		return fmt.Errorf("加载配置失败: %w", err)
 	}
 
 	// Handle reset flag
 	if configReset {
-		cfg = core.DefaultConfig()
-		if err := core.SaveConfig(cfg); err != nil {
+		cfg = gvmlib.DefaultConfig()
+		if err := m.SaveConfig(ctx, cfg); err != nil {
 			return fmt.Errorf("重置配置失败: %w", err)
 		}
 		fmt.Println("配置已重置为默认值")
-		printConfig(cfg)
-		return nil
+		return printConfig(cfg)
 	}
 
 	// Handle show flag
 	if configShow {
-		printConfig(cfg)
-		return nil
+		return printConfig(cfg)
 	}
 
 	// Handle setting values
@@ -77,25 +102,108 @@ func handleConfigCommand() error {
 		fmt.Printf("设置 download_source_json = %s\n", configSourceJSON)
 	}
 
+	if configCacheDir != "" {
+		if configCacheDir == "default" {
+			configCacheDir = ""
+		}
+		cfg.CacheDir = configCacheDir
+		modified = true
+		fmt.Printf("设置 cache_dir = %s\n", configCacheDir)
+	}
+
+	if changed, err := setHTTPConfig(cfg); err != nil {
+		return err
+	} else if changed {
+		modified = true
+	}
+
 	// If no flags were provided, show current config
 	if !modified {
-		printConfig(cfg)
-		return nil
+		return printConfig(cfg)
 	}
 
 	// Save the modified config
-	if err := core.SaveConfig(cfg); err != nil {
+	if err := m.SaveConfig(ctx, cfg); err != nil {
 		return fmt.Errorf("保存配置失败: %w", err)
 	}
 
 	fmt.Println("配置已保存")
+	if structured() {
+		return printConfig(cfg)
+	}
 	return nil
 }
 
-func printConfig(cfg *core.Config) {
-	fmt.Println("当前配置:")
-	fmt.Println("================")
-	b, _ := json.MarshalIndent(cfg, "", "  ")
-	fmt.Println(string(b))
-	fmt.Println("================")
+// setHTTPConfig applies the --http-* flags to cfg and checks that a client
+// can be built from the result
+func setHTTPConfig(cfg *gvmlib.Config) (bool, error) {
+	h := gvmlib.HTTPConfig{}
+	if cfg.HTTP != nil {
+		h = *cfg.HTTP
+	}
+	modified := false
+	set := func(key, value string, field *string, isPath bool) error {
+		if value == "" {
+			return nil
+		}
+		if value == "default" {
+			value = ""
+		} else if isPath {
+			abs, err := filepath.Abs(value)
+			if err != nil {
+				return err
+			}
+			value = abs
+		}
+		*field = value
+		modified = true
+		fmt.Printf("设置 http.%s = %s\n", key, value)
+		return nil
+	}
+	for _, err := range []error{
+		set("proxy", configHTTPProxy, &h.Proxy, false),
+		set("ca_file", configHTTPCAFile, &h.CAFile, true),
+		set("client_cert", configHTTPCert, &h.ClientCert, true),
+		set("client_key", configHTTPKey, &h.ClientKey, true),
+		set("timeout", configHTTPTimeout, &h.Timeout, false),
+	} {
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if configHTTPRetries != "" {
+		h.Retries = nil
+		if configHTTPRetries != "default" {
+			n, err := strconv.Atoi(configHTTPRetries)
+			if err != nil || n < 0 {
+				return false, fmt.Errorf("无效的重试次数: %s", configHTTPRetries)
+			}
+			h.Retries = &n
+		}
+		modified = true
+		fmt.Printf("设置 http.retries = %s\n", configHTTPRetries)
+	}
+
+	if !modified {
+		return false, nil
+	}
+	if _, err := gvmlib.NewHTTPClient(&h); err != nil {
+		return false, err
+	}
+	cfg.HTTP = &h
+	if h == (gvmlib.HTTPConfig{}) {
+		cfg.HTTP = nil
+	}
+	return true, nil
+}
+
+func printConfig(cfg *gvmlib.Config) error {
+	return render(cfg, func() {
+		fmt.Println("当前配置:")
+		fmt.Println("================")
+		b, _ := json.MarshalIndent(cfg, "", "  ")
+		fmt.Println(string(b))
+		fmt.Println("================")
+	})
 }
@@ -2,24 +2,55 @@ package gvm
 
 import (
 	"fmt"
+	"os"
 
-	"github.com/ibreez3/gvm/internal/core"
+	gvmlib "github.com/ibreez3/gvm/pkg/gvm"
 	"github.com/spf13/cobra"
 )
 
 var currentCmd = &cobra.Command{
 	Use:   "current",
 	Short: "查看当前版本",
+	Long: `查看当前生效的 Go 版本及其来源:
+  session  由 gvm shell 为当前终端设置 (GVM_VERSION)
+  project  由项目文件 (.go-version/go.work/go.mod) 指定
+  global   全局默认版本 (gvm use)`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		v, err := core.CurrentVersion()
+		dir, err := os.Getwd()
 		if err != nil {
 			return err
 		}
-		fmt.Println(v)
-		return nil
+		a, err := manager().Current(cmd.Context(), dir)
+		if err != nil {
+			return err
+		}
+		doc := currentDoc{Version: a.Version, Source: a.Source}
+		if a.Project != nil {
+			doc.File = a.Project.File
+			doc.Spec = a.Project.Spec
+		}
+		return render(doc, func() {
+			switch a.Source {
+			case gvmlib.SourceSession:
+				fmt.Printf("%s (%s: %s)\n", a.Version, a.Source, gvmlib.SessionEnvVar)
+			case gvmlib.SourceProject:
+				fmt.Printf("%s (%s: %s)\n", a.Version, a.Source, a.Project.File)
+			default:
+				fmt.Printf("%s (%s)\n", a.Version, a.Source)
+			}
+		})
 	},
 }
 
+// currentDoc is the structured output of current. File and Spec are set for
+// project versions.
+type currentDoc struct {
+	Version string               `json:"version"`
+	Source  gvmlib.VersionSource `json:"source"`
+	File    string               `json:"file,omitempty"`
+	Spec    string               `json:"spec,omitempty"`
+}
+
 func init() {
 	rootCmd.AddCommand(currentCmd)
 }
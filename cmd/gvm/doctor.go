@@ -0,0 +1,65 @@
+package gvm
+
+import (
+	"fmt"
+
+	gvmlib "github.com/ibreez3/gvm/pkg/gvm"
+	"github.com/spf13/cobra"
+)
+
+var doctorFix bool
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "诊断 gvm 运行环境",
+	Long: `检查常见的环境问题并给出修复建议:
+  - ~/.gvm/.gvmrc 缺失或过时 (如 $GGOBIN 拼写错误、$GOROOT/bin 被追加到 PATH 末尾)
+  - shell 配置文件未加载 ~/.gvm/.gvmrc
+  - goroot 软链接指向不存在的版本
+  - GOROOT 指向 gvm 之外的目录，或 PATH 中有其他 go 排在 gvm 之前
+  - shim 缺失
+  - 中断安装遗留的临时文件
+
+使用 --fix 自动修复可以安全修复的问题。`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		checks, err := manager().Doctor(cmd.Context())
+		if err != nil {
+			return err
+		}
+		problems := 0
+		for _, c := range checks {
+			switch c.Status {
+			case gvmlib.CheckOK:
+				fmt.Printf("✅ %s: %s\n", c.Name, c.Detail)
+				continue
+			case gvmlib.CheckWarn:
+				fmt.Printf("⚠️  %s: %s\n", c.Name, c.Detail)
+			default:
+				fmt.Printf("❌ %s: %s\n", c.Name, c.Detail)
+			}
+			if doctorFix && c.CanFix() {
+				if err := c.Fix(cmd.Context()); err != nil {
+					fmt.Printf("   修复失败: %v\n", err)
+					problems++
+				} else {
+					fmt.Println("   🔧 已修复")
+				}
+				continue
+			}
+			if c.Hint != "" {
+				fmt.Printf("   → %s\n", c.Hint)
+			}
+			problems++
+		}
+		if problems > 0 {
+			return fmt.Errorf("%d problem(s) found", problems)
+		}
+		return nil
+	},
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Automatically fix safe problems")
+	rootCmd.AddCommand(doctorCmd)
+}
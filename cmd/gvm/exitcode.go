@@ -0,0 +1,54 @@
+package gvm
+
+import (
+	"context"
+	"errors"
+
+	gvmlib "github.com/ibreez3/gvm/pkg/gvm"
+)
+
+// Exit codes returned by gvm. They are part of the CLI contract for scripts
+// and must not be renumbered.
+const (
+	ExitOK               = 0
+	ExitError            = 1   // any other failure
+	ExitInvalidVersion   = 2   // malformed version, constraint or argument
+	ExitVersionNotFound  = 3   // version not published by the download source
+	ExitNotInstalled     = 4   // version not installed locally
+	ExitAlreadyInstalled = 5   // version already installed (see install --if-missing)
+	ExitChecksumMismatch = 6   // downloaded or local archive failed verification
+	ExitUnsafeArchive    = 7   // archive contains entries escaping the install dir
+	ExitNetwork          = 8   // download source or GitHub unreachable or failing
+	ExitLocked           = 9   // another gvm process holds the lock
+	ExitInterrupted      = 130 // canceled by SIGINT/SIGTERM, partial changes rolled back
+)
+
+var exitCodes = []struct {
+	err  error
+	code int
+}{
+	// Checked first: an interrupted download also reports a network error
+	{gvmlib.ErrInterrupted, ExitInterrupted},
+	{context.Canceled, ExitInterrupted},
+	{gvmlib.ErrInvalidVersion, ExitInvalidVersion},
+	{gvmlib.ErrVersionNotFound, ExitVersionNotFound},
+	{gvmlib.ErrNotInstalled, ExitNotInstalled},
+	{gvmlib.ErrAlreadyInstalled, ExitAlreadyInstalled},
+	{gvmlib.ErrChecksumMismatch, ExitChecksumMismatch},
+	{gvmlib.ErrUnsafeArchive, ExitUnsafeArchive},
+	{gvmlib.ErrNetwork, ExitNetwork},
+	{gvmlib.ErrLocked, ExitLocked},
+}
+
+// exitCode maps an error returned by a command to its documented exit code
+func exitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	for _, e := range exitCodes {
+		if errors.Is(err, e.err) {
+			return e.code
+		}
+	}
+	return ExitError
+}
@@ -0,0 +1,55 @@
+package gvm
+
+import (
+	"fmt"
+	"strings"
+
+	gvmlib "github.com/ibreez3/gvm/pkg/gvm"
+	"github.com/spf13/cobra"
+)
+
+var infoCmd = &cobra.Command{
+	Use:   "info <version>",
+	Short: "查看已安装版本的安装信息",
+	Long: `查看已安装版本的安装信息：来源、安装包 SHA-256 与大小、安装时间及安装时的 gvm 版本。
+
+版本可以是具体版本、别名、关键字或版本约束。安装信息保存在 ~/.gvm/receipts/。`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		iv, err := manager().Info(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		if structured() {
+			return render(iv, nil)
+		}
+		fmt.Printf("版本:     go%s\n", iv.Version)
+		fmt.Printf("目录:     %s\n", iv.Dir)
+		if iv.Target != "" {
+			fmt.Printf("链接到:   %s\n", iv.Target)
+		}
+		if iv.Current {
+			fmt.Println("当前:     是")
+		}
+		if len(iv.Aliases) > 0 {
+			fmt.Printf("别名:     %s\n", strings.Join(iv.Aliases, ", "))
+		}
+		r := iv.Receipt
+		if r == nil {
+			fmt.Println("安装信息: 无 (由旧版本 gvm 安装)")
+			return nil
+		}
+		fmt.Printf("来源:     %s\n", r.Source)
+		if r.SHA256 != "" {
+			fmt.Printf("SHA-256:  %s\n", r.SHA256)
+			fmt.Printf("大小:     %s\n", strings.TrimSpace(gvmlib.FormatSize(r.Size)))
+		}
+		fmt.Printf("安装时间: %s\n", r.InstalledAt.Format("2006-01-02 15:04:05"))
+		fmt.Printf("gvm 版本: %s\n", r.GvmVersion)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(infoCmd)
+}
@@ -1,7 +1,6 @@
 package gvm
 
 import (
-	"github.com/ibreez3/gvm/internal/core"
 	"github.com/spf13/cobra"
 )
 
@@ -9,7 +8,7 @@ var initCmd = &cobra.Command{
 	Use:   "init",
 	Short: "Initialize gvm environment",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return core.InitEnv()
+		return manager().Init(cmd.Context())
 	},
 }
 
@@ -1,19 +1,83 @@
 package gvm
 
 import (
-	"github.com/ibreez3/gvm/internal/core"
+	"context"
+	"errors"
+	"fmt"
+
+	gvmlib "github.com/ibreez3/gvm/pkg/gvm"
 	"github.com/spf13/cobra"
 )
 
+var (
+	installFromFile  string
+	installFromURL   string
+	installSHA256    string
+	installIfMissing bool
+)
+
 var installCmd = &cobra.Command{
 	Use:   "install [version]",
 	Short: "Install a Go version",
-	Args:  cobra.ExactArgs(1),
+	Long: `Install a Go version.
+
+The version can be an exact version, a keyword or a constraint resolved
+against the remote index for this platform:
+  gvm install 1.22.5            # exact version
+  gvm install latest            # newest stable release (also: stable)
+  gvm install oldstable         # newest patch of the previous minor version
+  gvm install 1.22              # newest 1.22.x patch
+  gvm install ">=1.21 <1.23"    # newest version in range
+
+Archives can also be installed from a local file or an arbitrary URL, e.g. on
+air-gapped hosts. The version is detected from the archive's go/VERSION file:
+  gvm install --from-file go1.22.5.linux-amd64.tar.gz --sha256 <sum>
+  gvm install --from-url https://mirror.example.com/go1.22.5.linux-amd64.tar.gz
+
+With --if-missing an already installed version is not an error, which makes
+the command safe to repeat in provisioning scripts:
+  gvm install 1.22.5 --if-missing`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if installFromFile != "" || installFromURL != "" {
+			if len(args) > 0 {
+				return fmt.Errorf("不能同时指定版本和 --from-file/--from-url")
+			}
+			return nil
+		}
+		if installSHA256 != "" {
+			return fmt.Errorf("--sha256 只能与 --from-file 或 --from-url 一起使用")
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return core.InstallVersion(args[0])
+		err := runInstall(cmd.Context(), args)
+		if installIfMissing && errors.Is(err, gvmlib.ErrAlreadyInstalled) {
+			fmt.Printf("✅ %v, nothing to do\n", err)
+			return nil
+		}
+		return err
 	},
 }
 
+func runInstall(ctx context.Context, args []string) error {
+	switch {
+	case installFromFile != "" && installFromURL != "":
+		return fmt.Errorf("--from-file 和 --from-url 不能同时使用")
+	case installFromFile != "":
+		_, err := manager().InstallFile(ctx, installFromFile, installSHA256)
+		return err
+	case installFromURL != "":
+		_, err := manager().InstallURL(ctx, installFromURL, installSHA256)
+		return err
+	}
+	_, err := manager().Install(ctx, args[0])
+	return err
+}
+
 func init() {
+	installCmd.Flags().StringVar(&installFromFile, "from-file", "", "Install from a local Go archive")
+	installCmd.Flags().StringVar(&installFromURL, "from-url", "", "Download and install a Go archive from a URL")
+	installCmd.Flags().StringVar(&installSHA256, "sha256", "", "Expected SHA-256 of the archive (with --from-file/--from-url)")
+	installCmd.Flags().BoolVar(&installIfMissing, "if-missing", false, "Succeed without changes if the version is already installed")
 	rootCmd.AddCommand(installCmd)
 }
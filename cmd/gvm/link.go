@@ -1,7 +1,6 @@
 package gvm
 
 import (
-	"github.com/ibreez3/gvm/internal/core"
 	"github.com/spf13/cobra"
 )
 
@@ -10,7 +9,7 @@ var linkCmd = &cobra.Command{
 	Short: "Link an external Go SDK to gvm",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return core.LinkVersion(args[0])
+		return manager().Link(cmd.Context(), args[0])
 	},
 }
 
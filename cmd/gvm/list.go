@@ -1,8 +1,13 @@
 package gvm
 
 import (
+	"context"
 	"fmt"
-	"github.com/ibreez3/gvm/internal/core"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	gvmlib "github.com/ibreez3/gvm/pkg/gvm"
 	"github.com/spf13/cobra"
 )
 
@@ -12,33 +17,95 @@ var listCmd = &cobra.Command{
 	RunE: func(cmd *cobra.Command, args []string) error {
 		remote, _ := cmd.Flags().GetBool("remote")
 		if remote {
-			versions, err := core.ListRemote(20)
+			unstable, _ := cmd.Flags().GetBool("unstable")
+			versions, err := manager().ListRemote(cmd.Context(), 20, unstable)
 			if err != nil {
 				return err
 			}
-			for _, v := range versions {
-				fmt.Println(v)
-			}
-			return nil
+			return render(versionsDoc{Versions: nonNil(versions)}, func() {
+				for _, v := range versions {
+					fmt.Println(v)
+				}
+			})
 		}
-		
-		versions, err := core.ListLocal()
+
+		if long, _ := cmd.Flags().GetBool("long"); long || structured() {
+			return listLong(cmd.Context())
+		}
+
+		installed, err := manager().List(cmd.Context())
 		if err != nil {
 			return err
 		}
-		current, _ := core.CurrentVersion()
-		for _, v := range versions {
-			if v == current {
-				fmt.Printf("* %s\n", v)
+		for _, iv := range installed {
+			mark := " "
+			if iv.Current {
+				mark = "*"
+			}
+			if len(iv.Aliases) > 0 {
+				fmt.Printf("%s %s (%s)\n", mark, iv.Version, strings.Join(iv.Aliases, ", "))
 			} else {
-				fmt.Printf("  %s\n", v)
+				fmt.Printf("%s %s\n", mark, iv.Version)
 			}
 		}
 		return nil
 	},
 }
 
+// versionsDoc is the structured output of list -r and search
+type versionsDoc struct {
+	Versions []string `json:"versions"`
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// installedDoc is the structured output of list
+type installedDoc struct {
+	Versions []*gvmlib.InstalledVersion `json:"versions"`
+}
+
 func init() {
 	listCmd.Flags().BoolP("remote", "r", false, "List remote versions")
+	listCmd.Flags().Bool("unstable", false, "Include beta and release candidate versions (with -r)")
+	listCmd.Flags().BoolP("long", "l", false, "Show install source, time and size of installed versions")
 	rootCmd.AddCommand(listCmd)
 }
+
+// listLong prints installed versions with details from their install receipts
+func listLong(ctx context.Context) error {
+	installed, err := manager().List(ctx)
+	if err != nil {
+		return err
+	}
+	if structured() {
+		return render(installedDoc{Versions: installed}, nil)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, iv := range installed {
+		mark := " "
+		if iv.Current {
+			mark = "*"
+		}
+		name := iv.Version
+		if len(iv.Aliases) > 0 {
+			name += " (" + strings.Join(iv.Aliases, ", ") + ")"
+		}
+		r := iv.Receipt
+		switch {
+		case r == nil && iv.Target != "":
+			fmt.Fprintf(w, "%s %s\t-\tlinked\t%s\n", mark, name, iv.Target)
+		case r == nil:
+			fmt.Fprintf(w, "%s %s\t-\t-\t-\n", mark, name)
+		case r.Linked:
+			fmt.Fprintf(w, "%s %s\t%s\tlinked\t%s\n", mark, name, r.InstalledAt.Format("2006-01-02 15:04"), r.Source)
+		default:
+			fmt.Fprintf(w, "%s %s\t%s\t%s\t%s\n", mark, name, r.InstalledAt.Format("2006-01-02 15:04"), strings.TrimSpace(gvmlib.FormatSize(r.Size)), r.Source)
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,15 @@
+package gvm
+
+import (
+	gvmlib "github.com/ibreez3/gvm/pkg/gvm"
+)
+
+// manager returns the library Manager behind the commands. Status messages
+// and download progress are reported as selected by --progress and --quiet.
+func manager() *gvmlib.Manager {
+	return &gvmlib.Manager{
+		Logger:   logger,
+		Progress: progress,
+		Offline:  offline,
+	}
+}
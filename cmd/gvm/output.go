@@ -0,0 +1,49 @@
+package gvm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Output formats accepted by --output
+const (
+	outputText = "text"
+	outputJSON = "json"
+)
+
+var outputFormat = outputText
+
+// resultOut receives structured documents. In json mode os.Stdout is
+// pointed at stderr, so progress and decorative messages never mix with them.
+var resultOut io.Writer = os.Stdout
+
+// setupOutput validates --output and redirects decorative output for structured formats
+func setupOutput() error {
+	switch outputFormat {
+	case outputText:
+		return nil
+	case outputJSON:
+		resultOut = os.Stdout
+		os.Stdout = os.Stderr
+		return nil
+	}
+	return fmt.Errorf("unsupported output format %q (use text or json)", outputFormat)
+}
+
+// structured reports whether a machine-readable output format was requested
+func structured() bool {
+	return outputFormat != outputText
+}
+
+// render writes v as a JSON document, or calls text in text mode
+func render(v any, text func()) error {
+	if outputFormat == outputJSON {
+		enc := json.NewEncoder(resultOut)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	text()
+	return nil
+}
@@ -0,0 +1,53 @@
+package gvm
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	gvmlib "github.com/ibreez3/gvm/pkg/gvm"
+)
+
+// Progress modes accepted by --progress
+const (
+	progressAuto  = "auto"
+	progressBar   = "bar"
+	progressPlain = "plain"
+	progressJSON  = "json"
+	progressNone  = "none"
+)
+
+var (
+	progressMode = progressAuto
+	quiet        bool
+
+	// logger and progress are passed to the Manager behind the commands
+	logger   gvmlib.Logger = gvmlib.StdLogger{}
+	progress gvmlib.Progress
+)
+
+// setupProgress selects how downloads and status messages are reported. It
+// runs after setupOutput, so os.Stdout already points at stderr for
+// structured output formats.
+func setupProgress() error {
+	switch progressMode {
+	case progressAuto:
+		progress = gvmlib.AutoProgress(os.Stdout)
+	case progressBar:
+		progress = gvmlib.NewBarProgress(os.Stdout, os.Getenv("NO_COLOR") == "")
+	case progressPlain:
+		progress = gvmlib.NewLineProgress(os.Stdout, 5*time.Second)
+	case progressJSON:
+		progress = gvmlib.NewJSONProgress(os.Stdout, time.Second)
+		logger = gvmlib.NewJSONLogger(os.Stdout)
+	case progressNone:
+		progress = nil
+	default:
+		return fmt.Errorf("unsupported progress mode %q (use auto, bar, plain, json or none)", progressMode)
+	}
+	if quiet {
+		progress = nil
+		logger = gvmlib.QuietLogger{}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package gvm
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+var rehashCmd = &cobra.Command{
+	Use:   "rehash",
+	Short: "重新生成 go/gofmt shim",
+	Long: `重新生成 ~/.gvm/shims 下的 go 和 gofmt shim。
+
+shim 会依次根据 GVM_VERSION 环境变量、项目文件 (.go-version/go.work/go.mod)
+和全局默认版本选择要执行的 Go 版本，因此不同终端可以同时使用不同版本。
+移动 gvm 可执行文件后需要重新运行此命令。`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		m := manager()
+		if err := m.Rehash(cmd.Context()); err != nil {
+			return err
+		}
+		dir, _ := m.ShimsDir(cmd.Context())
+		fmt.Printf("shim 已生成: %s\n", dir)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rehashCmd)
+}
@@ -0,0 +1,67 @@
+package gvm
+
+import (
+	"fmt"
+	"os"
+
+	gvmlib "github.com/ibreez3/gvm/pkg/gvm"
+	"github.com/spf13/cobra"
+)
+
+var resolveExport bool
+
+var resolveCmd = &cobra.Command{
+	Use:   "resolve [dir]",
+	Short: "显示当前项目使用的 Go 版本",
+	Long: `根据项目文件解析当前目录应使用的 Go 版本，并从已安装版本中选择最合适的补丁版本。
+
+查找顺序 (从当前目录向上逐级查找，距离最近的文件优先):
+  1. .go-version
+  2. go.work 的 toolchain / go 指令
+  3. go.mod 的 toolchain / go 指令 (位于 go.work 工作区内时以 go.work 为准)
+
+使用 --export 时输出仅作用于当前 shell 的 GOROOT/PATH 设置语句，供 shell hook 使用：
+  eval "$(gvm resolve --export)"`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		if len(args) == 1 {
+			dir = args[0]
+		}
+		if resolveExport {
+			lines, err := manager().ProjectEnv(cmd.Context(), dir)
+			if err != nil && lines == nil {
+				return err
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "gvm: %v\n", err)
+			}
+			for _, l := range lines {
+				fmt.Println(l)
+			}
+			return nil
+		}
+		r, err := manager().Resolve(cmd.Context(), dir)
+		if err != nil {
+			return err
+		}
+		fmt.Println(r.Version)
+		fmt.Printf("来源: %s\n", describeProjectVersion(&r.ProjectVersion))
+		return nil
+	},
+}
+
+func init() {
+	resolveCmd.Flags().BoolVar(&resolveExport, "export", false, "输出切换到项目版本的 shell 语句 (不修改全局版本)")
+	rootCmd.AddCommand(resolveCmd)
+}
+
+func describeProjectVersion(pv *gvmlib.ProjectVersion) string {
+	if pv.Directive == "" {
+		return fmt.Sprintf("%s (%s)", pv.File, pv.Spec)
+	}
+	return fmt.Sprintf("%s (%s %s)", pv.File, pv.Directive, pv.Spec)
+}
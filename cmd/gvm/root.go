@@ -1,9 +1,15 @@
 package gvm
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
 
+	"github.com/ibreez3/gvm/internal/core"
 	"github.com/spf13/cobra"
 )
 
@@ -18,13 +24,58 @@ var rootCmd = &cobra.Command{
 	Short:   "Go Version Manager",
 	Long:    `gvm is a Go Version Manager that helps you manage multiple Go versions.`,
 	Version: version,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := setupOutput(); err != nil {
+			return err
+		}
+		return setupProgress()
+	},
+}
+
+var offline bool
+
+func init() {
+	rootCmd.PersistentFlags().BoolVar(&offline, "offline", false, "Only use the cached version index, never contact the download source (or GVM_OFFLINE=1)")
+	rootCmd.PersistentFlags().StringVar(&progressMode, "progress", progressAuto, "Download progress: auto (bar on terminals, periodic lines otherwise), bar, plain, json (NDJSON events) or none")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Hide download progress and status messages; warnings and errors are still printed")
+	rootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", outputText, "Output format for list, search, current, config, uninstall, upgrade and self-update: text or json")
 }
 
 // Execute runs the root command
 func Execute() {
+	// Invoked through a shim (~/.gvm/shims/go): dispatch to the selected version
+	if name, ok := core.ShimName(os.Args[0]); ok {
+		runShim(name, os.Args[1:])
+	}
 	rootCmd.SetVersionTemplate(fmt.Sprintf("gvm version %s (commit: %s, date: %s)\n", version, commit, date))
-	if err := rootCmd.Execute(); err != nil {
+	// Release builds set version through ldflags; receipts and self-update read it from core
+	core.GvmVersion = version
+
+	// Ctrl-C or SIGTERM cancels the running operation, which removes its
+	// partial files before returning. A second signal exits immediately.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+	if err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitCode(err))
+	}
+}
+
+// runShim executes the shimmed command and exits with its status
+func runShim(name string, args []string) {
+	err := core.ExecShim(context.Background(), name, args)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.ExitCode())
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "gvm: %v\n", err)
 		os.Exit(1)
 	}
+	os.Exit(0)
 }
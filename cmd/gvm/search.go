@@ -2,7 +2,6 @@ package gvm
 
 import (
 	"fmt"
-	"github.com/ibreez3/gvm/internal/core"
 	"github.com/spf13/cobra"
 )
 
@@ -11,17 +10,20 @@ var searchCmd = &cobra.Command{
 	Short: "Search for Go versions",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		versions, err := core.SearchRemote(args[0], 20)
+		prerelease, _ := cmd.Flags().GetBool("include-prerelease")
+		versions, err := manager().Search(cmd.Context(), args[0], 20, prerelease)
 		if err != nil {
 			return err
 		}
-		for _, v := range versions {
-			fmt.Println(v)
-		}
-		return nil
+		return render(versionsDoc{Versions: nonNil(versions)}, func() {
+			for _, v := range versions {
+				fmt.Println(v)
+			}
+		})
 	},
 }
 
 func init() {
+	searchCmd.Flags().Bool("include-prerelease", false, "Include beta and release candidate versions")
 	rootCmd.AddCommand(searchCmd)
 }
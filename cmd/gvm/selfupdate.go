@@ -3,7 +3,6 @@ package gvm
 import (
 	"fmt"
 
-	"github.com/ibreez3/gvm/internal/core"
 	"github.com/spf13/cobra"
 )
 
@@ -15,24 +14,33 @@ var selfUpdateCmd = &cobra.Command{
 		checkOnly, _ := cmd.Flags().GetBool("check")
 
 		if checkOnly {
-			hasUpdate, latest, err := core.CheckUpdate()
+			hasUpdate, latest, err := manager().CheckUpdate(cmd.Context())
 			if err != nil {
 				return err
 			}
-			fmt.Printf("当前版本: %s\n", core.GvmVersion)
-			fmt.Printf("最新版本: %s\n", latest)
-			if hasUpdate {
-				fmt.Println("有新版本可用!")
-			} else {
-				fmt.Println("已经是最新版本")
-			}
-			return nil
+			doc := updateCheckDoc{Current: version, Latest: latest, UpdateAvailable: hasUpdate}
+			return render(doc, func() {
+				fmt.Printf("当前版本: %s\n", version)
+				fmt.Printf("最新版本: %s\n", latest)
+				if hasUpdate {
+					fmt.Println("有新版本可用!")
+				} else {
+					fmt.Println("已经是最新版本")
+				}
+			})
 		}
 
-		return core.SelfUpdate()
+		return manager().SelfUpdate(cmd.Context())
 	},
 }
 
+// updateCheckDoc is the structured output of self-update --check
+type updateCheckDoc struct {
+	Current         string `json:"current"`
+	Latest          string `json:"latest"`
+	UpdateAvailable bool   `json:"update_available"`
+}
+
 func init() {
 	selfUpdateCmd.Flags().Bool("check", false, "仅检查是否有新版本，不进行更新")
 	rootCmd.AddCommand(selfUpdateCmd)
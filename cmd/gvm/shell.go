@@ -0,0 +1,64 @@
+package gvm
+
+import (
+	"fmt"
+	"os"
+
+	gvmlib "github.com/ibreez3/gvm/pkg/gvm"
+	"github.com/spf13/cobra"
+)
+
+var shellUnset bool
+
+var shellCmd = &cobra.Command{
+	Use:   "shell [version]",
+	Short: "仅为当前终端切换 Go 版本",
+	Long: `仅为当前终端切换 Go 版本，不修改全局默认版本。
+
+命令会输出设置 GOROOT、PATH 和 GVM_VERSION 的 shell 语句。通过 gvm init 加载
+~/.gvm/.gvmrc 后，gvm 函数会自动执行这些语句；否则需要手动 eval:
+  eval "$(gvm shell 1.22.5)"
+
+示例:
+  gvm shell 1.22.5    # 当前终端使用 go1.22.5
+  gvm shell --unset   # 恢复为项目版本或全局默认版本`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if shellUnset {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var lines []string
+		if shellUnset {
+			dir, err := os.Getwd()
+			if err != nil {
+				return err
+			}
+			lines, err = manager().UnsetSessionEnv(cmd.Context(), dir)
+			if lines == nil {
+				return err
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "gvm: %v\n", err)
+			}
+		} else {
+			var err error
+			if lines, err = manager().SessionEnv(cmd.Context(), args[0]); err != nil {
+				return err
+			}
+		}
+		for _, l := range lines {
+			fmt.Println(l)
+		}
+		if gvmlib.IsTerminal(os.Stdout) {
+			fmt.Fprintln(os.Stderr, `# 以上语句需要在当前 shell 中执行: eval "$(gvm shell ...)"，或运行 gvm init 后重新打开终端`)
+		}
+		return nil
+	},
+}
+
+func init() {
+	shellCmd.Flags().BoolVar(&shellUnset, "unset", false, "取消当前终端的版本设置")
+	rootCmd.AddCommand(shellCmd)
+}
@@ -1,9 +1,11 @@
 package gvm
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
-	"github.com/ibreez3/gvm/internal/core"
+	gvmlib "github.com/ibreez3/gvm/pkg/gvm"
 	"github.com/spf13/cobra"
 )
 
@@ -13,6 +15,7 @@ var (
 	uninstallKeep      int
 	uninstallAll       bool
 	uninstallKeepCurrent bool
+	uninstallForce     bool
 )
 
 var uninstallCmd = &cobra.Command{
@@ -27,16 +30,18 @@ var uninstallCmd = &cobra.Command{
   gvm uninstall --keep 2         # 只保留最新的 2 个版本，卸载其余
   gvm uninstall --all            # 卸载所有版本
 
-注意: 使用批量卸载时会自动跳过当前正在使用的版本。`,
+注意: 使用批量卸载时会自动跳过当前正在使用的版本。
+被别名引用的版本需要加 --force 才能卸载，同时会删除对应的别名。`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Batch mode: no version specified, use flags
 		if len(args) == 0 {
-			spec := &core.UninstallBatchSpec{
+			spec := &gvmlib.UninstallBatchSpec{
 				Below:       uninstallBelow,
 				Pattern:     uninstallPattern,
 				Keep:        uninstallKeep,
 				All:         uninstallAll,
 				KeepCurrent: uninstallKeepCurrent,
+				Force:       uninstallForce,
 			}
 
 			// Validate that exactly one batch option is specified
@@ -58,12 +63,22 @@ var uninstallCmd = &cobra.Command{
 				return fmt.Errorf("请指定一个批量卸载选项: --below, --pattern, --keep, 或 --all")
 			}
 
-			uninstalled, err := core.UninstallBatch(spec)
-			if err != nil {
+			uninstalled, err := manager().UninstallBatch(cmd.Context(), spec)
+			var batchErr *gvmlib.BatchUninstallError
+			if err != nil && !errors.As(err, &batchErr) {
 				return err
 			}
-			fmt.Printf("\n✅ 成功卸载 %d 个版本\n", len(uninstalled))
-			return nil
+			doc := uninstallDoc{Uninstalled: nonNil(uninstalled)}
+			if batchErr != nil {
+				doc.Failed = batchErr.Failed
+			}
+			if rerr := render(doc, func() {
+				fmt.Printf("\n✅ 成功卸载 %d 个版本\n", len(uninstalled))
+			}); rerr != nil {
+				return rerr
+			}
+			// Failed versions are listed in the error and make the exit code non-zero
+			return err
 		}
 
 		// Single version mode
@@ -71,15 +86,25 @@ var uninstallCmd = &cobra.Command{
 			return fmt.Errorf("不能同时指定版本和批量卸载选项")
 		}
 
-		return core.UninstallVersion(args[0])
+		if err := manager().Uninstall(cmd.Context(), args[0], uninstallForce); err != nil {
+			return err
+		}
+		return render(uninstallDoc{Uninstalled: []string{strings.TrimPrefix(args[0], "go")}}, func() {})
 	},
 }
 
+// uninstallDoc is the structured output of uninstall
+type uninstallDoc struct {
+	Uninstalled []string                  `json:"uninstalled"`
+	Failed      []gvmlib.UninstallFailure `json:"failed,omitempty"`
+}
+
 func init() {
 	uninstallCmd.Flags().StringVar(&uninstallBelow, "below", "", "卸载低于此版本的所有版本")
 	uninstallCmd.Flags().StringVar(&uninstallPattern, "pattern", "", "卸载匹配此模式的所有版本 (支持通配符，如 1.21.*)")
 	uninstallCmd.Flags().IntVar(&uninstallKeep, "keep", 0, "只保留最新的 N 个版本，卸载其余")
 	uninstallCmd.Flags().BoolVar(&uninstallAll, "all", false, "卸载所有版本")
 	uninstallCmd.Flags().BoolVarP(&uninstallKeepCurrent, "keep-current", "c", true, "保留当前正在使用的版本")
+	uninstallCmd.Flags().BoolVarP(&uninstallForce, "force", "f", false, "强制卸载被别名引用的版本 (同时删除别名)")
 	rootCmd.AddCommand(uninstallCmd)
 }
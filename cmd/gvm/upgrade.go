@@ -1,15 +1,19 @@
 package gvm
 
 import (
+	"bufio"
+	"context"
 	"fmt"
+	"os"
+	"strings"
 
-	"github.com/ibreez3/gvm/internal/core"
+	gvmlib "github.com/ibreez3/gvm/pkg/gvm"
 	"github.com/spf13/cobra"
 )
 
 var (
-	upgradeUse   bool
-	upgradeYes   bool
+	upgradeUse bool
+	upgradeYes bool
 )
 
 var upgradeCmd = &cobra.Command{
@@ -22,25 +26,71 @@ var upgradeCmd = &cobra.Command{
   gvm upgrade go1.25  # 同上 (支持 go 前缀)`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		version, err := core.UpgradeVersion(args[0])
+		m := manager()
+		result, err := m.Upgrade(cmd.Context(), args[0])
 		if err != nil {
 			return err
 		}
+		version := result.To
+
+		if err := moveStaleAliases(cmd.Context(), m, version); err != nil {
+			return err
+		}
 
 		// Automatically use the newly upgraded version if requested
 		if upgradeUse {
-			if err := core.UseVersion(version); err != nil {
+			if _, err := m.Use(cmd.Context(), version); err != nil {
 				return err
 			}
 			fmt.Printf("已切换到 go%s\n", version)
 		}
 
-		return nil
+		return render(upgradeDoc{UpgradeResult: result, Switched: upgradeUse}, func() {})
 	},
 }
 
+// upgradeDoc is the structured output of upgrade
+type upgradeDoc struct {
+	*gvmlib.UpgradeResult
+	// Switched reports whether the upgraded version became the global default
+	Switched bool `json:"switched"`
+}
+
 func init() {
 	upgradeCmd.Flags().BoolVarP(&upgradeUse, "use", "u", false, "升级后自动切换到新版本")
 	upgradeCmd.Flags().BoolVarP(&upgradeYes, "yes", "y", false, "自动确认升级")
 	rootCmd.AddCommand(upgradeCmd)
 }
+
+// moveStaleAliases offers to point aliases of older patches at the upgraded version
+func moveStaleAliases(ctx context.Context, m *gvmlib.Manager, version string) error {
+	names, err := m.StaleAliases(ctx, version)
+	if err != nil {
+		return err
+	}
+	aliases, err := m.Aliases(ctx)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if !upgradeYes && !confirm(fmt.Sprintf("是否将别名 %s (go%s) 指向 go%s? (y/N): ", name, aliases[name], version)) {
+			continue
+		}
+		if _, err := m.SetAlias(ctx, name, version); err != nil {
+			return err
+		}
+		fmt.Printf("别名 %s -> go%s\n", name, version)
+	}
+	return nil
+}
+
+// confirm asks a yes/no question on stdin, defaulting to no
+func confirm(prompt string) bool {
+	fmt.Print(prompt)
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true
+	}
+	return false
+}
@@ -1,19 +1,54 @@
 package gvm
 
 import (
-	"github.com/ibreez3/gvm/internal/core"
+	"fmt"
+	"os"
+
 	"github.com/spf13/cobra"
 )
 
+var useProject bool
+
 var useCmd = &cobra.Command{
 	Use:   "use [version]",
 	Short: "Switch to a Go version",
-	Args:  cobra.ExactArgs(1),
+	Long: `Switch to a Go version.
+
+The version can be an exact version, a keyword (latest, stable, oldstable),
+a minor version such as 1.22 or a constraint such as ">=1.21 <1.23",
+resolved against the installed versions.
+
+With --project, the version is resolved from .go-version, go.work or go.mod
+in the current directory or its parents (see "gvm resolve").`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if useProject {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.ExactArgs(1)(cmd, args)
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return core.UseVersion(args[0])
+		if !useProject {
+			_, err := manager().Use(cmd.Context(), args[0])
+			return err
+		}
+		dir, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		m := manager()
+		r, err := m.Resolve(cmd.Context(), dir)
+		if err != nil {
+			return err
+		}
+		if _, err := m.Use(cmd.Context(), r.Version); err != nil {
+			return err
+		}
+		fmt.Printf("已切换到 go%s (%s)\n", r.Version, describeProjectVersion(&r.ProjectVersion))
+		return nil
 	},
 }
 
 func init() {
+	useCmd.Flags().BoolVar(&useProject, "project", false, "使用当前项目 (.go-version/go.work/go.mod) 指定的版本")
 	rootCmd.AddCommand(useCmd)
 }
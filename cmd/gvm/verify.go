@@ -0,0 +1,97 @@
+package gvm
+
+import (
+	"errors"
+	"fmt"
+
+	gvmlib "github.com/ibreez3/gvm/pkg/gvm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	verifyAll    bool
+	verifyRepair bool
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [version]",
+	Short: "校验已安装版本的文件完整性",
+	Long: `将已安装版本的文件与安装时记录的清单 (文件路径及 SHA-256) 进行比对，
+报告被修改、缺失和多出的文件。
+
+使用 --repair 会从下载缓存 (缓存中不存在时重新下载) 重新解压，恢复为原始状态。
+链接的版本和旧版本 gvm 安装的版本没有清单，无法校验。
+
+示例:
+  gvm verify 1.22.5           # 校验指定版本
+  gvm verify --all            # 校验所有已安装版本
+  gvm verify 1.22.5 --repair  # 校验并修复`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if verifyAll == (len(args) == 1) {
+			return fmt.Errorf("specify a version or --all")
+		}
+		m := manager()
+		var results []*gvmlib.VerifyResult
+		if verifyAll {
+			rs, err := m.VerifyAll(cmd.Context())
+			if err != nil {
+				return err
+			}
+			results = rs
+		} else {
+			r, err := m.Verify(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			results = append(results, r)
+		}
+
+		failed := 0
+		for _, r := range results {
+			if r.NoManifest {
+				fmt.Printf("⚠️  go%s: no manifest recorded, skipped\n", r.Version)
+				continue
+			}
+			if r.OK() {
+				fmt.Printf("✅ go%s: %d files OK\n", r.Version, r.Files)
+				continue
+			}
+			printVerifyResult(r)
+			if !verifyRepair {
+				failed++
+				continue
+			}
+			fmt.Printf("🔧 Repairing go%s...\n", r.Version)
+			if err := m.Repair(cmd.Context(), r.Version); err != nil {
+				fmt.Printf("⚠️  Failed to repair go%s: %v\n", r.Version, err)
+				failed++
+				continue
+			}
+			fmt.Printf("✅ Repaired go%s\n", r.Version)
+		}
+		if failed > 0 {
+			return errors.New("verification failed, run with --repair to restore the original files")
+		}
+		return nil
+	},
+}
+
+func printVerifyResult(r *gvmlib.VerifyResult) {
+	for _, p := range r.Modified {
+		fmt.Printf("  modified: %s\n", p)
+	}
+	for _, p := range r.Missing {
+		fmt.Printf("  missing:  %s\n", p)
+	}
+	for _, p := range r.Extra {
+		fmt.Printf("  extra:    %s\n", p)
+	}
+	fmt.Printf("❌ go%s: %d modified, %d missing, %d extra\n", r.Version, len(r.Modified), len(r.Missing), len(r.Extra))
+}
+
+func init() {
+	verifyCmd.Flags().BoolVar(&verifyAll, "all", false, "Verify all installed versions")
+	verifyCmd.Flags().BoolVar(&verifyRepair, "repair", false, "Restore modified versions from the download cache (or re-download)")
+	rootCmd.AddCommand(verifyCmd)
+}
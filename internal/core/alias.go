@@ -0,0 +1,145 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var aliasNameRe = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.-]*$`)
+
+// AliasesPath returns the path to the aliases file
+func AliasesPath(ctx context.Context) (string, error) {
+	d, err := GvmDir(ctx)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(d, "aliases.json"), nil
+}
+
+// LoadAliases returns the alias name to version mapping
+func LoadAliases(ctx context.Context) (map[string]string, error) {
+	p, err := AliasesPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	aliases := map[string]string{}
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", p, err)
+	}
+	return aliases, nil
+}
+
+func saveAliases(ctx context.Context, aliases map[string]string) error {
+	p, err := AliasesPath(ctx)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	return writeJSONFile(p, aliases)
+}
+
+// SetAlias points name at an installed version. The version may be a keyword or
+// constraint; the alias always stores the concrete version it resolved to.
+func SetAlias(ctx context.Context, name, version string) (string, error) {
+	var v string
+	err := withLock(ctx, func(ctx context.Context) error {
+		var err error
+		v, err = setAlias(ctx, name, version)
+		return err
+	})
+	return v, err
+}
+
+func setAlias(ctx context.Context, name, version string) (string, error) {
+	if !aliasNameRe.MatchString(name) {
+		return "", fmt.Errorf("invalid alias name: %s", name)
+	}
+	if _, err := ParseVersion(name); err == nil || isKeyword(name) {
+		return "", fmt.Errorf("alias name %s conflicts with a version or keyword", name)
+	}
+	v, _, err := resolveInstalledVersion(ctx, version)
+	if err != nil {
+		return "", err
+	}
+	aliases, err := LoadAliases(ctx)
+	if err != nil {
+		return "", err
+	}
+	aliases[name] = v
+	return v, saveAliases(ctx, aliases)
+}
+
+// RemoveAlias deletes an alias
+func RemoveAlias(ctx context.Context, name string) error {
+	return withLock(ctx, func(ctx context.Context) error { return removeAlias(ctx, name) })
+}
+
+func removeAlias(ctx context.Context, name string) error {
+	aliases, err := LoadAliases(ctx)
+	if err != nil {
+		return err
+	}
+	if _, ok := aliases[name]; !ok {
+		return fmt.Errorf("alias %s not found", name)
+	}
+	delete(aliases, name)
+	return saveAliases(ctx, aliases)
+}
+
+// AliasesFor returns the sorted alias names pointing at version
+func AliasesFor(aliases map[string]string, version string) []string {
+	version = strings.TrimPrefix(version, "go")
+	var names []string
+	for name, v := range aliases {
+		if v == version {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StaleAliases returns the sorted alias names pointing at an older patch of
+// the same minor version as version, i.e. the aliases an upgrade could move
+func StaleAliases(ctx context.Context, version string) ([]string, error) {
+	nv, err := ParseVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	aliases, err := LoadAliases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for name, s := range aliases {
+		if v, err := ParseVersion(s); err == nil && v.SameMinor(nv) && v.Compare(nv) < 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func isKeyword(s string) bool {
+	switch strings.ToLower(s) {
+	case KeywordLatest, KeywordStable, KeywordOldstable:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,79 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakeRoot returns a context for a temporary gvm directory with the given
+// versions installed, and the directory
+func fakeRoot(t *testing.T, versions ...string) (context.Context, string) {
+	t.Helper()
+	root := t.TempDir()
+	for _, v := range versions {
+		if err := os.MkdirAll(filepath.Join(root, "go"+v, "bin"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return WithSettings(context.Background(), Settings{Root: root}), root
+}
+
+func TestAliasResolution(t *testing.T) {
+	ctx, _ := fakeRoot(t, "1.21.13", "1.22.5", "1.23.1")
+	for name, version := range map[string]string{
+		"golden":  "1.21.13",
+		"gopher":  "1.22",
+		"go-prod": "1.23.1",
+		"prod":    "go1.22.5",
+	} {
+		if _, err := SetAlias(ctx, name, version); err != nil {
+			t.Fatalf("SetAlias(%s, %s): %v", name, version, err)
+		}
+	}
+
+	tests := []struct {
+		spec         string
+		want         string
+		wantResolved bool
+	}{
+		{"golden", "1.21.13", true},
+		{"gopher", "1.22.5", true},
+		{"go-prod", "1.23.1", true},
+		{"prod", "1.22.5", true},
+		{"1.22.5", "1.22.5", false},
+		{"go1.22.5", "1.22.5", false},
+		{"go1.21", "1.21.13", true},
+		{"latest", "1.23.1", true},
+	}
+	for _, tt := range tests {
+		got, resolved, err := resolveInstalledVersion(ctx, tt.spec)
+		if err != nil || got != tt.want || resolved != tt.wantResolved {
+			t.Errorf("resolveInstalledVersion(%q) = %q, %v, %v; want %q, %v", tt.spec, got, resolved, err, tt.want, tt.wantResolved)
+		}
+	}
+}
+
+func TestSetAliasRejectsVersionNames(t *testing.T) {
+	ctx, _ := fakeRoot(t, "1.22.5")
+	for _, name := range []string{"1.22", "go1.22", "latest", "Stable", "-x", "a b"} {
+		if _, err := SetAlias(ctx, name, "1.22.5"); err == nil {
+			t.Errorf("SetAlias(%q) succeeded, want an error", name)
+		}
+	}
+}
+
+func TestAliasToUninstalledVersion(t *testing.T) {
+	ctx, root := fakeRoot(t, "1.22.5")
+	if _, err := SetAlias(ctx, "golden", "1.22.5"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(filepath.Join(root, "go1.22.5")); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := resolveInstalledVersion(ctx, "golden"); !errors.Is(err, ErrNotInstalled) {
+		t.Errorf("resolveInstalledVersion(golden) = %v, want ErrNotInstalled", err)
+	}
+}
@@ -0,0 +1,476 @@
+package core
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// archiveExt returns the extension of the official Go archive for osys
+func archiveExt(osys string) string {
+	if osys == "windows" {
+		return ".zip"
+	}
+	return ".tar.gz"
+}
+
+// Archive formats supported by extractArchive
+const (
+	formatTarGz = "tar.gz"
+	formatZip   = "zip"
+)
+
+// archiveFormat detects the archive format from the file name
+func archiveFormat(archive string) (string, error) {
+	name := strings.ToLower(filepath.Base(archive))
+	switch {
+	case strings.HasSuffix(name, ".zip"):
+		return formatZip, nil
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return formatTarGz, nil
+	}
+	return "", fmt.Errorf("unsupported archive format: %s (expected .tar.gz, .tgz or .zip)", filepath.Base(archive))
+}
+
+// extractArchive extracts a .tar.gz/.tgz or .zip archive into dest. It stops
+// between entries once ctx is canceled.
+func extractArchive(ctx context.Context, archive, dest string) error {
+	format, err := archiveFormat(archive)
+	if err != nil {
+		return err
+	}
+	if format == formatZip {
+		return unzip(ctx, archive, dest)
+	}
+	return untar(ctx, archive, dest)
+}
+
+// archiveVersion reads the Go version from the go/VERSION file of an archive
+func archiveVersion(archive string) (string, error) {
+	format, err := archiveFormat(archive)
+	if err != nil {
+		return "", err
+	}
+	if format == formatZip {
+		zr, err := zip.OpenReader(archive)
+		if err != nil {
+			return "", err
+		}
+		defer zr.Close()
+		for _, f := range zr.File {
+			if path.Clean(f.Name) == "go/VERSION" {
+				rc, err := f.Open()
+				if err != nil {
+					return "", err
+				}
+				defer rc.Close()
+				return parseVersionFile(rc)
+			}
+		}
+		return "", fmt.Errorf("go/VERSION not found in %s", archive)
+	}
+
+	f, err := os.Open(archive)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return "", err
+	}
+	defer gr.Close()
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return "", fmt.Errorf("go/VERSION not found in %s", archive)
+		}
+		if err != nil {
+			return "", err
+		}
+		if path.Clean(hdr.Name) == "go/VERSION" {
+			return parseVersionFile(tr)
+		}
+	}
+}
+
+// ExtractError reports an archive entry that was rejected during extraction
+type ExtractError struct {
+	// Entry is the name of the offending entry as stored in the archive
+	Entry string
+	// Reason explains why the entry was rejected
+	Reason string
+}
+
+func (e *ExtractError) Error() string {
+	return fmt.Sprintf("unsafe archive entry %q: %s", e.Entry, e.Reason)
+}
+
+// Unwrap makes errors.Is(err, ErrUnsafeArchive) match extraction errors
+func (e *ExtractError) Unwrap() error {
+	return ErrUnsafeArchive
+}
+
+// extractUmask is applied to the modes stored in archives. Only permission
+// bits are kept, so setuid, setgid and sticky bits are always dropped.
+const extractUmask = 0o022
+
+// extractor writes archive entries below dest. Entries must stay inside dest:
+// absolute names, ".." components, symlinks pointing outside dest and writes
+// through previously extracted symlinks are rejected with an *ExtractError.
+//
+// Symlinks are created last, once all directories exist, and their targets
+// may only pass through real directories. A target therefore cannot be
+// redirected by another symlink of the archive, whether it comes earlier or
+// later. Hardlinks may only point at regular files written by the extractor.
+type extractor struct {
+	dest string
+	// dirs are directories known to be real directories inside dest
+	dirs map[string]bool
+	// files are the regular files written so far, the valid hardlink targets
+	files map[string]bool
+	// symlinks are created by finish
+	symlinks []symlink
+	// dirTimes are applied last, since creating entries updates directory mtimes
+	dirTimes []dirTime
+}
+
+type symlink struct {
+	name, target string
+}
+
+type dirTime struct {
+	path  string
+	mtime time.Time
+}
+
+func newExtractor(dest string) (*extractor, error) {
+	abs, err := filepath.Abs(dest)
+	if err != nil {
+		return nil, err
+	}
+	return &extractor{dest: abs, dirs: map[string]bool{abs: true}, files: map[string]bool{}}, nil
+}
+
+// path maps an entry name to its location below dest
+func (x *extractor) path(name string) (string, error) {
+	clean := path.Clean(strings.ReplaceAll(name, `\`, "/"))
+	if path.IsAbs(clean) || filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", &ExtractError{Entry: name, Reason: "absolute path"}
+	}
+	if clean == ".." || strings.HasPrefix(clean, "../") {
+		return "", &ExtractError{Entry: name, Reason: "path escapes the destination directory"}
+	}
+	if clean == "." {
+		return x.dest, nil
+	}
+	return filepath.Join(x.dest, filepath.FromSlash(clean)), nil
+}
+
+// mkdirParents creates the parent directories of p, refusing to traverse symlinks
+func (x *extractor) mkdirParents(name, p string) error {
+	return x.mkdir(name, filepath.Dir(p), 0o755)
+}
+
+func (x *extractor) mkdir(name, p string, perm os.FileMode) error {
+	if x.dirs[p] {
+		return nil
+	}
+	if parent := filepath.Dir(p); parent != p {
+		if err := x.mkdirParents(name, p); err != nil {
+			return err
+		}
+	}
+	fi, err := os.Lstat(p)
+	switch {
+	case err == nil && fi.Mode()&os.ModeSymlink != 0:
+		return &ExtractError{Entry: name, Reason: "path traverses a symlink"}
+	case err == nil && !fi.IsDir():
+		return &ExtractError{Entry: name, Reason: "path conflicts with an existing file"}
+	case os.IsNotExist(err):
+		if err := os.Mkdir(p, perm|0o700); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	}
+	x.dirs[p] = true
+	return nil
+}
+
+// prepare validates an entry name and returns its path with parents created.
+// An existing non-directory at the path is removed so it is never written through.
+func (x *extractor) prepare(name string) (string, error) {
+	p, err := x.path(name)
+	if err != nil {
+		return "", err
+	}
+	if p == x.dest {
+		return "", &ExtractError{Entry: name, Reason: "entry replaces the destination directory"}
+	}
+	if err := x.mkdirParents(name, p); err != nil {
+		return "", err
+	}
+	if fi, err := os.Lstat(p); err == nil {
+		if fi.IsDir() {
+			return "", &ExtractError{Entry: name, Reason: "path conflicts with an existing directory"}
+		}
+		if err := os.Remove(p); err != nil {
+			return "", err
+		}
+	}
+	return p, nil
+}
+
+func (x *extractor) dir(name string, mode os.FileMode, mtime time.Time) error {
+	p, err := x.path(name)
+	if err != nil {
+		return err
+	}
+	if err := x.mkdir(name, p, safeMode(mode)); err != nil {
+		return err
+	}
+	if err := os.Chmod(p, safeMode(mode)|0o700); err != nil {
+		return err
+	}
+	if !mtime.IsZero() {
+		x.dirTimes = append(x.dirTimes, dirTime{p, mtime})
+	}
+	return nil
+}
+
+func (x *extractor) file(name string, r io.Reader, mode os.FileMode, mtime time.Time) error {
+	p, err := x.prepare(name)
+	if err != nil {
+		return err
+	}
+	of, err := os.OpenFile(p, os.O_CREATE|os.O_EXCL|os.O_WRONLY, safeMode(mode))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(of, r); err != nil {
+		of.Close()
+		return err
+	}
+	if err := of.Close(); err != nil {
+		return err
+	}
+	x.files[p] = true
+	if !mtime.IsZero() {
+		return os.Chtimes(p, mtime, mtime)
+	}
+	return nil
+}
+
+// symlink validates a symlink entry; it is created by finish
+func (x *extractor) symlink(name, target string) error {
+	if target == "" || filepath.IsAbs(target) || path.IsAbs(target) || filepath.VolumeName(target) != "" {
+		return &ExtractError{Entry: name, Reason: fmt.Sprintf("absolute symlink target %q", target)}
+	}
+	p, err := x.path(name)
+	if err != nil {
+		return err
+	}
+	if p == x.dest {
+		return &ExtractError{Entry: name, Reason: "entry replaces the destination directory"}
+	}
+	x.symlinks = append(x.symlinks, symlink{name, target})
+	return nil
+}
+
+// createSymlink walks target from the symlink's directory. Every component
+// but the last must be a real directory inside dest, so the link resolves
+// inside dest whatever the last component turns out to be.
+func (x *extractor) createSymlink(name, target string) error {
+	p, err := x.prepare(name)
+	if err != nil {
+		return err
+	}
+	var parts []string
+	for _, part := range strings.Split(strings.ReplaceAll(target, `\`, "/"), "/") {
+		if part != "" && part != "." {
+			parts = append(parts, part)
+		}
+	}
+	cur := filepath.Dir(p)
+	for i, part := range parts {
+		if part == ".." {
+			cur = filepath.Dir(cur)
+		} else {
+			cur = filepath.Join(cur, part)
+		}
+		if !x.inside(cur) {
+			return &ExtractError{Entry: name, Reason: fmt.Sprintf("symlink target %q escapes the destination directory", target)}
+		}
+		if i == len(parts)-1 {
+			break
+		}
+		if fi, err := os.Lstat(cur); err != nil || !fi.IsDir() {
+			return &ExtractError{Entry: name, Reason: fmt.Sprintf("symlink target %q passes through %s, which is not an extracted directory", target, cur)}
+		}
+	}
+	return os.Symlink(target, p)
+}
+
+// inside reports whether p is dest or below it
+func (x *extractor) inside(p string) bool {
+	rel, err := filepath.Rel(x.dest, p)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// hardlink links name to target, an earlier regular file entry of the archive
+func (x *extractor) hardlink(name, target string) error {
+	src, err := x.path(target)
+	if err != nil || !x.files[src] {
+		return &ExtractError{Entry: name, Reason: fmt.Sprintf("hardlink target %q is not an extracted regular file", target)}
+	}
+	p, err := x.prepare(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Link(src, p); err == nil {
+		x.files[p] = true
+		return nil
+	}
+	// Filesystems without hardlinks: fall back to a copy
+	fi, err := os.Lstat(src)
+	if err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	return x.file(name, in, fi.Mode(), fi.ModTime())
+}
+
+// finish creates the symlinks and applies directory modification times,
+// deepest first
+func (x *extractor) finish() error {
+	for _, l := range x.symlinks {
+		if err := x.createSymlink(l.name, l.target); err != nil {
+			return err
+		}
+	}
+	for i := len(x.dirTimes) - 1; i >= 0; i-- {
+		if err := os.Chtimes(x.dirTimes[i].path, x.dirTimes[i].mtime, x.dirTimes[i].mtime); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func safeMode(mode os.FileMode) os.FileMode {
+	return (mode.Perm() &^ extractUmask) | 0o600
+}
+
+func untar(ctx context.Context, tgz string, dest string) error {
+	f, err := os.Open(tgz)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	if err != nil {
+		return err
+	}
+	defer gr.Close()
+	x, err := newExtractor(dest)
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gr)
+	for {
+		if err := interrupted(ctx); err != nil {
+			return err
+		}
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		mode := os.FileMode(hdr.Mode)
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			err = x.dir(hdr.Name, mode, hdr.ModTime)
+		case tar.TypeReg:
+			err = x.file(hdr.Name, tr, mode, hdr.ModTime)
+		case tar.TypeSymlink:
+			err = x.symlink(hdr.Name, hdr.Linkname)
+		case tar.TypeLink:
+			err = x.hardlink(hdr.Name, hdr.Linkname)
+		default:
+			// Devices, FIFOs and PAX metadata have no place in a Go distribution
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return x.finish()
+}
+
+func unzip(ctx context.Context, archive string, dest string) error {
+	zr, err := zip.OpenReader(archive)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	x, err := newExtractor(dest)
+	if err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		if err := interrupted(ctx); err != nil {
+			return err
+		}
+		mode := f.Mode()
+		switch {
+		case mode.IsDir():
+			err = x.dir(f.Name, mode, f.Modified)
+		case mode&os.ModeSymlink != 0:
+			var target string
+			if target, err = readZipEntry(f); err == nil {
+				err = x.symlink(f.Name, target)
+			}
+		case mode.IsRegular():
+			err = x.zipFile(f)
+		default:
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return x.finish()
+}
+
+func (x *extractor) zipFile(f *zip.File) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return x.file(f.Name, rc, f.Mode(), f.Modified)
+}
+
+// readZipEntry returns the content of a small entry, e.g. a symlink target
+func readZipEntry(f *zip.File) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	b, err := io.ReadAll(io.LimitReader(rc, 4096))
+	return string(b), err
+}
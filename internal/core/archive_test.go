@@ -0,0 +1,289 @@
+package core
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// entry describes one archive member for the generated fixtures
+type entry struct {
+	name string
+	// body is the file content, or the target of a symlink or hardlink
+	body  string
+	mode  os.FileMode
+	link  bool // hardlink (tar only)
+	mtime time.Time
+}
+
+func dirEntry(name string) entry        { return entry{name: name, mode: os.ModeDir | 0o755} }
+func fileEntry(name, body string) entry { return entry{name: name, body: body, mode: 0o644} }
+func symlinkEntry(name, target string) entry {
+	return entry{name: name, body: target, mode: os.ModeSymlink | 0o777}
+}
+
+func writeTarGz(t *testing.T, entries []entry) string {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: int64(e.mode.Perm()), ModTime: e.mtime}
+		if e.mode&os.ModeSetuid != 0 {
+			hdr.Mode |= 0o4000
+		}
+		if e.mode&os.ModeSetgid != 0 {
+			hdr.Mode |= 0o2000
+		}
+		switch {
+		case e.link:
+			hdr.Typeflag, hdr.Linkname = tar.TypeLink, e.body
+		case e.mode.IsDir():
+			hdr.Typeflag = tar.TypeDir
+		case e.mode&os.ModeSymlink != 0:
+			hdr.Typeflag, hdr.Linkname = tar.TypeSymlink, e.body
+		default:
+			hdr.Typeflag, hdr.Size = tar.TypeReg, int64(len(e.body))
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(e.body)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(t.TempDir(), "go.tar.gz")
+	if err := os.WriteFile(p, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// formats generates the same entries as a .tar.gz and a .zip
+var formats = []struct {
+	name  string
+	write func(*testing.T, []entry) string
+}{
+	{"tar", writeTarGz},
+	{"zip", writeZip},
+}
+
+// extract extracts entries into a fresh directory below a parent holding a
+// canary file, which escaping entries would reach
+func extract(t *testing.T, write func(*testing.T, []entry) string, entries []entry) (string, error) {
+	t.Helper()
+	archive := write(t, entries)
+	parent := t.TempDir()
+	if err := os.WriteFile(filepath.Join(parent, "canary"), []byte("host"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	dest := filepath.Join(parent, "dest")
+	if err := os.Mkdir(dest, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return dest, extractArchive(context.Background(), archive, dest)
+}
+
+func skipWithoutSymlinks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks need extra privileges on Windows")
+	}
+}
+
+func TestExtractRejectsUnsafeEntries(t *testing.T) {
+	// chain is go/da/db/.../dt, 20 directories deep
+	chain, up := "go", ""
+	for i := 0; i < 20; i++ {
+		chain += "/d" + string(rune('a'+i))
+		up += "../"
+	}
+
+	tests := []struct {
+		name     string
+		symlinks bool
+		entries  []entry
+	}{
+		{name: "dotdot", entries: []entry{fileEntry("go/../../canary", "x")}},
+		{name: "dotdot prefix", entries: []entry{fileEntry("../canary", "x")}},
+		{name: "absolute", entries: []entry{fileEntry("/tmp/gvm-archive-test", "x")}},
+		{name: "symlink escape", symlinks: true, entries: []entry{
+			symlinkEntry("go/esc", "../../canary"),
+		}},
+		{name: "absolute symlink", symlinks: true, entries: []entry{
+			symlinkEntry("go/esc", "/etc"),
+		}},
+		{name: "write through symlink", symlinks: true, entries: []entry{
+			dirEntry("go/real"),
+			symlinkEntry("go/sub", "real"),
+			fileEntry("go/sub/f", "x"),
+			symlinkEntry("go/sub", "../.."),
+		}},
+		{name: "symlink chain", symlinks: true, entries: []entry{
+			dirEntry(chain),
+			// s1 resolves to go, which is inside dest...
+			symlinkEntry(chain+"/s1", up[:len(up)-3]),
+			// ...but esc follows it and then climbs 20 more levels
+			symlinkEntry("go/esc", chain[3:]+"/s1/"+up),
+		}},
+		{name: "symlink chain forward reference", symlinks: true, entries: []entry{
+			dirEntry("go"),
+			// b does not exist yet; it later points at dest
+			symlinkEntry("go/a", "b/../.."),
+			symlinkEntry("go/b", ".."),
+		}},
+	}
+	for _, f := range formats {
+		for _, tt := range tests {
+			t.Run(f.name+"/"+tt.name, func(t *testing.T) {
+				if tt.symlinks {
+					skipWithoutSymlinks(t)
+				}
+				dest, err := extract(t, f.write, tt.entries)
+				if !errors.Is(err, ErrUnsafeArchive) {
+					t.Fatalf("err = %v, want ErrUnsafeArchive", err)
+				}
+				var xe *ExtractError
+				if !errors.As(err, &xe) {
+					t.Fatalf("err = %T, want *ExtractError", err)
+				}
+				if b, _ := os.ReadFile(filepath.Join(dest, "..", "canary")); string(b) != "host" {
+					t.Fatalf("canary outside dest was modified: %q", b)
+				}
+			})
+		}
+	}
+}
+
+func TestExtractHardlinks(t *testing.T) {
+	skipWithoutSymlinks(t)
+	tests := []struct {
+		name    string
+		entries []entry
+		wantErr bool
+	}{
+		{name: "regular file", entries: []entry{
+			fileEntry("go/bin/go", "binary"),
+			{name: "go/pkg/tool/go", body: "go/bin/go", link: true},
+		}},
+		{name: "outside dest", wantErr: true, entries: []entry{
+			{name: "go/leak", body: "../canary", link: true},
+		}},
+		{name: "missing target", wantErr: true, entries: []entry{
+			{name: "go/leak", body: "go/nope", link: true},
+		}},
+		{name: "through symlink", wantErr: true, entries: []entry{
+			dirEntry("go/real"),
+			fileEntry("go/real/f", "x"),
+			symlinkEntry("go/esc", "real"),
+			{name: "go/leak", body: "go/esc/f", link: true},
+		}},
+		{name: "symlink target", wantErr: true, entries: []entry{
+			fileEntry("go/f", "x"),
+			symlinkEntry("go/l", "f"),
+			{name: "go/leak", body: "go/l", link: true},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest, err := extract(t, writeTarGz, tt.entries)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnsafeArchive) {
+					t.Fatalf("err = %v, want ErrUnsafeArchive", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := os.ReadFile(filepath.Join(dest, "go/pkg/tool/go"))
+			if err != nil || string(b) != "binary" {
+				t.Fatalf("hardlink content = %q, %v", b, err)
+			}
+		})
+	}
+}
+
+func TestExtractSymlinks(t *testing.T) {
+	skipWithoutSymlinks(t)
+	for _, f := range formats {
+		t.Run(f.name, func(t *testing.T) {
+			dest, err := extract(t, f.write, []entry{
+				dirEntry("go/lib"),
+				fileEntry("go/lib/f", "x"),
+				dirEntry("go/bin"),
+				symlinkEntry("go/bin/f", "../lib/f"),
+				// targets may point at entries extracted later
+				symlinkEntry("go/bin/g", "../misc/g"),
+				symlinkEntry("go/self", "."),
+				fileEntry("go/misc/g", "y"),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for name, want := range map[string]string{"go/bin/f": "x", "go/bin/g": "y", "go/self/lib/f": "x"} {
+				if b, err := os.ReadFile(filepath.Join(dest, name)); err != nil || string(b) != want {
+					t.Errorf("%s = %q, %v; want %q", name, b, err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestExtractModesAndTimes(t *testing.T) {
+	fileTime := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	dirTime := time.Date(2023, 1, 2, 3, 4, 6, 0, time.UTC)
+	for _, f := range formats {
+		t.Run(f.name, func(t *testing.T) {
+			dest, err := extract(t, f.write, []entry{
+				{name: "go/bin/", mode: os.ModeDir | 0o777, mtime: dirTime},
+				{name: "go/bin/go", body: "binary", mode: 0o777 | os.ModeSetuid | os.ModeSetgid, mtime: fileTime},
+				{name: "go/bin/ro", body: "x", mode: 0o400, mtime: fileTime},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			fi, err := os.Stat(filepath.Join(dest, "go/bin/go"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fi.Mode()&(os.ModeSetuid|os.ModeSetgid|os.ModeSticky) != 0 {
+				t.Errorf("go/bin/go mode = %v, want setuid/setgid dropped", fi.Mode())
+			}
+			if runtime.GOOS != "windows" {
+				if perm := fi.Mode().Perm(); perm&0o022 != 0 || perm&0o100 == 0 {
+					t.Errorf("go/bin/go perm = %o, want executable and not group/world writable", perm)
+				}
+				if ro, err := os.Stat(filepath.Join(dest, "go/bin/ro")); err != nil || ro.Mode().Perm()&0o600 != 0o600 {
+					t.Errorf("go/bin/ro perm = %v, %v; want owner read/write", ro.Mode(), err)
+				}
+			}
+			if !fi.ModTime().Equal(fileTime) {
+				t.Errorf("go/bin/go mtime = %v, want %v", fi.ModTime(), fileTime)
+			}
+
+			di, err := os.Stat(filepath.Join(dest, "go/bin"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !di.ModTime().Equal(dirTime) {
+				t.Errorf("go/bin mtime = %v, want %v", di.ModTime(), dirTime)
+			}
+		})
+	}
+}
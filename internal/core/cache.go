@@ -0,0 +1,363 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CachedArchive is a verified Go archive in the download cache
+type CachedArchive struct {
+	Filename string
+	SHA256   string
+	Path     string
+	Size     int64
+	// LastUsed is when the archive was downloaded or last reused for an install
+	LastUsed time.Time
+}
+
+// CacheDir returns the directory holding cached downloads.
+// It defaults to ~/.gvm/cache and can be changed with the cache_dir config
+// option, e.g. to share archives between several machines.
+func CacheDir(ctx context.Context) (string, error) {
+	cfg, err := LoadConfig(ctx)
+	if err != nil {
+		return "", err
+	}
+	if cfg.CacheDir != "" {
+		return expandHome(cfg.CacheDir)
+	}
+	d, err := GvmDir(ctx)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(d, "cache"), nil
+}
+
+// archivesDir returns the content-addressed archive store: archives/<sha256>/<filename>
+func archivesDir(ctx context.Context) (string, error) {
+	c, err := CacheDir(ctx)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(c, "archives"), nil
+}
+
+// fetchArchive returns the path of a verified archive for url, reusing the
+// download cache when an archive with the expected checksum is present.
+// Without a known checksum the archive is downloaded, hashed and then stored.
+func fetchArchive(ctx context.Context, url, filename, sum string) (string, error) {
+	dir, err := archivesDir(ctx)
+	if err != nil {
+		return "", err
+	}
+	sum = strings.ToLower(sum)
+
+	if cached, ok := cachedArchive(ctx, dir, filename, sum); ok {
+		return cached, nil
+	}
+	if IsOffline(ctx) {
+		return "", errorf(ErrNetwork, "offline mode: %s is not in the download cache", filename)
+	}
+
+	tmpDir := filepath.Join(dir, "tmp")
+	if err := os.MkdirAll(tmpDir, 0o755); err != nil {
+		return "", err
+	}
+	tmp := filepath.Join(tmpDir, filename)
+
+	// The cache may be shared with other hosts, which the gvm lock does not
+	// cover. Whoever holds the download lock owns tmp and its partial files;
+	// the others wait and then reuse the finished archive.
+	lock, err := lockFile(ctx, tmp+".lock")
+	if err != nil {
+		return "", err
+	}
+	defer releaseLock(lock)
+	if cached, ok := cachedArchive(ctx, dir, filename, sum); ok {
+		return cached, nil
+	}
+
+	logf(ctx, "⬇️  Downloading %s\n", filename)
+	logf(ctx, "🔗 Source: %s\n", url)
+	if err := downloadFile(ctx, url, tmp); err != nil {
+		return "", err
+	}
+
+	if sum != "" {
+		logf(ctx, "🛡️  Verifying checksum...\n")
+		if err := verifyChecksum(tmp, sum); err != nil {
+			os.Remove(tmp) // 删除损坏的文件
+			return "", fmt.Errorf("checksum verification failed: %w", err)
+		}
+		logf(ctx, "✅ Checksum verified\n")
+	} else {
+		logf(ctx, "⚠️  Skipping checksum verification (not available)\n")
+		if sum, err = fileSHA256(tmp); err != nil {
+			return "", err
+		}
+	}
+
+	cached := filepath.Join(dir, sum, filename)
+	if err := os.MkdirAll(filepath.Dir(cached), 0o755); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp, cached); err != nil {
+		return "", err
+	}
+	return cached, nil
+}
+
+// cachedArchive returns the cached archive with checksum sum, if it is
+// present and intact
+func cachedArchive(ctx context.Context, dir, filename, sum string) (string, bool) {
+	if sum == "" {
+		return "", false
+	}
+	cached := filepath.Join(dir, sum, filename)
+	if _, err := os.Stat(cached); err != nil {
+		return "", false
+	}
+	if err := verifyChecksum(cached, sum); err != nil {
+		_ = os.Remove(cached)
+		return "", false
+	}
+	logf(ctx, "♻️  Using cached archive: %s\n", cached)
+	now := time.Now()
+	_ = os.Chtimes(cached, now, now)
+	return cached, true
+}
+
+// ListCachedArchives returns the archives in the download cache, oldest first
+func ListCachedArchives(ctx context.Context) ([]CachedArchive, error) {
+	dir, err := archivesDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sums, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []CachedArchive
+	for _, s := range sums {
+		if !s.IsDir() || len(s.Name()) != sha256.Size*2 {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(dir, s.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			fi, err := f.Info()
+			if err != nil || !fi.Mode().IsRegular() {
+				continue
+			}
+			out = append(out, CachedArchive{
+				Filename: f.Name(),
+				SHA256:   s.Name(),
+				Path:     filepath.Join(dir, s.Name(), f.Name()),
+				Size:     fi.Size(),
+				LastUsed: fi.ModTime(),
+			})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].LastUsed.Before(out[j].LastUsed) })
+	return out, nil
+}
+
+// ownedCachePaths returns the entries of the cache directory written by gvm:
+// the archive store and the version index files. Anything else in a shared
+// cache_dir is left alone.
+func ownedCachePaths(ctx context.Context) ([]string, error) {
+	dir, err := CacheDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+	adir, err := archivesDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// index-<hash>.json and index-<hash>.meta.json
+	index, err := filepath.Glob(filepath.Join(dir, "index-*.json"))
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{adir}, index...), nil
+}
+
+// CacheSize returns the total size in bytes of the archives and version
+// index files in the cache directory
+func CacheSize(ctx context.Context) (int64, error) {
+	paths, err := ownedCachePaths(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, p := range paths {
+		err = filepath.WalkDir(p, func(_ string, e os.DirEntry, err error) error {
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if e.Type().IsRegular() {
+				if fi, err := e.Info(); err == nil {
+					total += fi.Size()
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// CleanCache removes cached archives and partial downloads not used within
+// olderThan. A zero olderThan removes all of them and the version index.
+// Other files in the cache directory and downloads in progress, possibly on
+// another host sharing the cache, are kept. It returns the number of bytes freed.
+func CleanCache(ctx context.Context, olderThan time.Duration) (int64, error) {
+	var freed int64
+	err := withLock(ctx, func(ctx context.Context) error {
+		var err error
+		freed, err = cleanCache(ctx, olderThan)
+		return err
+	})
+	return freed, err
+}
+
+func cleanCache(ctx context.Context, olderThan time.Duration) (int64, error) {
+	adir, err := archivesDir(ctx)
+	if err != nil {
+		return 0, err
+	}
+	tmpDir := filepath.Join(adir, "tmp")
+	cutoff := time.Now().Add(-olderThan)
+	var freed int64
+	remove := func(p string, fi os.FileInfo) error {
+		if err := os.Remove(p); err != nil {
+			return err
+		}
+		freed += fi.Size()
+		return nil
+	}
+
+	err = filepath.WalkDir(adir, func(p string, e os.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !e.Type().IsRegular() {
+			return nil
+		}
+		fi, err := e.Info()
+		if err != nil || (olderThan > 0 && !fi.ModTime().Before(cutoff)) {
+			return nil
+		}
+		if filepath.Dir(p) == tmpDir {
+			// Lock files stay: removing one while it is held would let a
+			// second process lock a new file at the same path
+			if strings.HasSuffix(p, ".lock") || downloadInProgress(p) {
+				return nil
+			}
+			return remove(p, fi)
+		}
+		if err := remove(p, fi); err != nil {
+			return err
+		}
+		// Drop the now empty <sha256> directory
+		if parent := filepath.Dir(p); parent != adir {
+			_ = os.Remove(parent)
+		}
+		return nil
+	})
+	if err != nil || olderThan > 0 {
+		return freed, err
+	}
+
+	paths, err := ownedCachePaths(ctx)
+	if err != nil {
+		return freed, err
+	}
+	for _, p := range paths[1:] { // the version index files
+		if fi, err := os.Lstat(p); err == nil && fi.Mode().IsRegular() {
+			if err := remove(p, fi); err != nil {
+				return freed, err
+			}
+		}
+	}
+	return freed, nil
+}
+
+// downloadInProgress reports whether p, a download or its partial files in
+// archives/tmp, belongs to a download whose lock is held
+func downloadInProgress(p string) bool {
+	base := strings.TrimSuffix(strings.TrimSuffix(p, ".json"), ".part")
+	f, err := os.OpenFile(base+".lock", os.O_RDWR, 0)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	ok, err := tryLockFile(f)
+	if ok {
+		unlockFile(f)
+	}
+	return err == nil && !ok
+}
+
+// ParseAge parses a duration that also accepts a day suffix, e.g. "30d" or "12h"
+func ParseAge(s string) (time.Duration, error) {
+	if n, ok := strings.CutSuffix(s, "d"); ok {
+		days, err := strconv.Atoi(n)
+		if err != nil || days < 0 {
+			return 0, fmt.Errorf("invalid duration: %s", s)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration: %s", s)
+	}
+	return d, nil
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// expandHome replaces a leading ~ with the home directory
+func expandHome(p string) (string, error) {
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p, nil
+	}
+	h, err := HomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(h, strings.TrimPrefix(p, "~")), nil
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"os"
@@ -13,6 +14,10 @@ type Config struct {
 	DownloadSource string `json:"download_source"`
 	// DownloadSourceJSON is the JSON API endpoint for version info (default: https://go.dev/dl/?mode=json&include=all)
 	DownloadSourceJSON string `json:"download_source_json"`
+	// CacheDir is where downloaded archives and the version index are cached (default: ~/.gvm/cache)
+	CacheDir string `json:"cache_dir,omitempty"`
+	// HTTP configures proxy, TLS, timeout and retries of downloads
+	HTTP *HTTPConfig `json:"http,omitempty"`
 }
 
 const (
@@ -31,8 +36,8 @@ func DefaultConfig() *Config {
 }
 
 // ConfigPath returns the path to the config file
-func ConfigPath() (string, error) {
-	d, err := GvmDir()
+func ConfigPath(ctx context.Context) (string, error) {
+	d, err := GvmDir(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -41,8 +46,8 @@ func ConfigPath() (string, error) {
 
 // LoadConfig loads the configuration from the config file
 // If the file doesn't exist, returns a default config
-func LoadConfig() (*Config, error) {
-	configPath, err := ConfigPath()
+func LoadConfig(ctx context.Context) (*Config, error) {
+	configPath, err := ConfigPath(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -75,8 +80,12 @@ func LoadConfig() (*Config, error) {
 }
 
 // SaveConfig saves the configuration to the config file
-func SaveConfig(cfg *Config) error {
-	configPath, err := ConfigPath()
+func SaveConfig(ctx context.Context, cfg *Config) error {
+	return withLock(ctx, func(ctx context.Context) error { return saveConfig(ctx, cfg) })
+}
+
+func saveConfig(ctx context.Context, cfg *Config) error {
+	configPath, err := ConfigPath(ctx)
 	if err != nil {
 		return err
 	}
@@ -86,17 +95,12 @@ func SaveConfig(cfg *Config) error {
 		return err
 	}
 
-	data, err := json.MarshalIndent(cfg, "", "  ")
-	if err != nil {
-		return err
-	}
-
-	return os.WriteFile(configPath, data, 0o644)
+	return writeJSONFile(configPath, cfg)
 }
 
 // GetDownloadSource returns the configured download source URL
-func GetDownloadSource() (string, error) {
-	cfg, err := LoadConfig()
+func GetDownloadSource(ctx context.Context) (string, error) {
+	cfg, err := LoadConfig(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -104,8 +108,8 @@ func GetDownloadSource() (string, error) {
 }
 
 // GetDownloadSourceJSON returns the configured JSON API endpoint
-func GetDownloadSourceJSON() (string, error) {
-	cfg, err := LoadConfig()
+func GetDownloadSourceJSON(ctx context.Context) (string, error) {
+	cfg, err := LoadConfig(ctx)
 	if err != nil {
 		return "", err
 	}
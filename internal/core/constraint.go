@@ -0,0 +1,248 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Version keywords accepted by install, use and shell
+const (
+	// KeywordLatest selects the newest stable release
+	KeywordLatest = "latest"
+	// KeywordStable selects the newest patch of the newest minor version
+	KeywordStable = "stable"
+	// KeywordOldstable selects the newest patch of the previous minor version
+	KeywordOldstable = "oldstable"
+)
+
+// Constraint is a set of version ranges, e.g. ">=1.21 <1.23" or "~1.22 || ^1.24".
+// Comparators separated by spaces or commas must all match; alternatives
+// separated by "||" are OR-ed.
+type Constraint struct {
+	alts [][]comparator
+}
+
+type comparator struct {
+	op string
+	v  Version
+}
+
+// ParseConstraint parses a version constraint.
+// Supported operators: =, !=, >, >=, <, <=, ~ (same minor, not lower) and ^ (same major, not lower).
+// A bare minor version such as "1.22" is equivalent to "~1.22", a bare major
+// version such as "1" to "^1".
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{}
+	for _, alt := range strings.Split(s, "||") {
+		var cmps []comparator
+		for _, tok := range strings.FieldsFunc(alt, func(r rune) bool { return r == ' ' || r == ',' }) {
+			op := ""
+			for _, o := range []string{">=", "<=", "!=", ">", "<", "=", "~", "^"} {
+				if strings.HasPrefix(tok, o) {
+					op = o
+					break
+				}
+			}
+			vs := strings.TrimSpace(strings.TrimPrefix(tok, op))
+			v, err := ParseVersion(vs)
+			if err != nil {
+				return nil, errorf(ErrInvalidVersion, "invalid version constraint %q: %v", s, err)
+			}
+			if op == "" {
+				op = "="
+				if !isExactVersion(vs) {
+					op = "~"
+				}
+			}
+			if op == "~" && !strings.Contains(vs, ".") {
+				// "1" and "~1" mean any 1.x, not 1.0.x
+				op = "^"
+			}
+			cmps = append(cmps, comparator{op: op, v: v})
+		}
+		if len(cmps) == 0 {
+			return nil, errorf(ErrInvalidVersion, "invalid version constraint %q", s)
+		}
+		c.alts = append(c.alts, cmps)
+	}
+	return c, nil
+}
+
+// Check reports whether v satisfies the constraint
+func (c *Constraint) Check(v Version) bool {
+	for _, cmps := range c.alts {
+		ok := true
+		for _, cmp := range cmps {
+			if !cmp.check(v) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c comparator) check(v Version) bool {
+	d := v.Compare(c.v)
+	switch c.op {
+	case "=":
+		return d == 0
+	case "!=":
+		return d != 0
+	case ">":
+		return d > 0
+	case ">=":
+		return d >= 0
+	case "<":
+		return d < 0
+	case "<=":
+		return d <= 0
+	case "~":
+		return d >= 0 && v.SameMinor(c.v)
+	case "^":
+		return d >= 0 && v.Major == c.v.Major
+	}
+	return false
+}
+
+// isExactVersion reports whether spec names a single release (e.g. "1.22.5" or "1.24rc2")
+// rather than a minor version line such as "1.22"
+func isExactVersion(spec string) bool {
+	spec = strings.TrimPrefix(spec, "go")
+	v, err := ParseVersion(spec)
+	return err == nil && (v.IsPrerelease() || strings.Count(spec, ".") >= 2)
+}
+
+// resolveSpec selects the version matching spec among candidates.
+// spec is a keyword (latest, stable, oldstable), an exact version or a constraint.
+// Pre-releases are only selected by an exact version.
+func resolveSpec(spec string, candidates []string) (string, error) {
+	spec = strings.TrimSpace(spec)
+	if isExactVersion(spec) {
+		want, _ := ParseVersion(spec)
+		for _, c := range candidates {
+			if v, err := ParseVersion(c); err == nil && v.Compare(want) == 0 {
+				return c, nil
+			}
+		}
+		return "", errorf(ErrVersionNotFound, "version %s not found", strings.TrimPrefix(spec, "go"))
+	}
+
+	var stable []string
+	for _, c := range candidates {
+		if v, err := ParseVersion(c); err == nil && !v.IsPrerelease() {
+			stable = append(stable, c)
+		}
+	}
+	sortVersions(stable)
+	if len(stable) == 0 {
+		return "", errorf(ErrVersionNotFound, "no versions available for %s", spec)
+	}
+
+	switch strings.ToLower(spec) {
+	case KeywordLatest, KeywordStable:
+		return stable[len(stable)-1], nil
+	case KeywordOldstable:
+		newest, _ := ParseVersion(stable[len(stable)-1])
+		for i := len(stable) - 1; i >= 0; i-- {
+			if v, _ := ParseVersion(stable[i]); !v.SameMinor(newest) {
+				return stable[i], nil
+			}
+		}
+		return "", errorf(ErrVersionNotFound, "no versions available for %s", spec)
+	}
+
+	c, err := ParseConstraint(spec)
+	if err != nil {
+		return "", err
+	}
+	for i := len(stable) - 1; i >= 0; i-- {
+		if v, _ := ParseVersion(stable[i]); c.Check(v) {
+			return stable[i], nil
+		}
+	}
+	return "", errorf(ErrVersionNotFound, "no versions match %s", spec)
+}
+
+// ResolveRemoteSpec resolves spec against the versions published for this platform
+func ResolveRemoteSpec(ctx context.Context, spec string) (string, error) {
+	all, err := FetchIndex(ctx)
+	if err != nil {
+		return "", err
+	}
+	return resolveSpec(spec, remoteCandidates(all, spec, runtime.GOOS, runtime.GOARCH))
+}
+
+// remoteCandidates returns the versions in all that have an archive for
+// goos/goarch. Unstable versions are only included for an exact spec.
+func remoteCandidates(all []DLVersion, spec, goos, goarch string) []string {
+	var candidates []string
+	for _, dv := range all {
+		v, err := ParseVersion(dv.Version)
+		if err != nil || (isUnstable(dv, v) && !isExactVersion(spec)) {
+			continue
+		}
+		for _, f := range dv.Files {
+			if f.OS == goos && f.Arch == goarch && f.Kind == "archive" {
+				candidates = append(candidates, v.String())
+				break
+			}
+		}
+	}
+	return candidates
+}
+
+// ResolveLocalSpec resolves spec against the installed versions
+func ResolveLocalSpec(ctx context.Context, spec string) (string, error) {
+	installed, err := ListLocal(ctx)
+	if err != nil {
+		return "", err
+	}
+	v, err := resolveSpec(spec, installed)
+	if errors.Is(err, ErrVersionNotFound) {
+		return "", errorf(ErrNotInstalled, "%v (installed: %s)", err, strings.Join(installed, ", "))
+	}
+	if err != nil {
+		return "", err
+	}
+	return v, nil
+}
+
+// resolveInstalledVersion maps spec to an installed version. An installed
+// directory named after spec wins, then aliases; otherwise spec is resolved as
+// a keyword or constraint. resolved reports whether spec had to be resolved.
+func resolveInstalledVersion(ctx context.Context, spec string) (version string, resolved bool, err error) {
+	d, err := GvmDir(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	if _, err := ParseVersion(spec); err == nil {
+		spec = strings.TrimPrefix(spec, "go")
+		if _, err := os.Stat(filepath.Join(d, "go"+spec)); err == nil {
+			return spec, false, nil
+		}
+	}
+	aliases, err := LoadAliases(ctx)
+	if err != nil {
+		return "", false, err
+	}
+	// Alias names may start with "go" (e.g. "golden"), so look them up as given
+	if v, ok := aliases[spec]; ok {
+		if _, err := os.Stat(filepath.Join(d, "go"+v)); err != nil {
+			return "", false, errorf(ErrNotInstalled, "alias %s points to go%s, which is not installed", spec, v)
+		}
+		return v, true, nil
+	}
+	v, err := ResolveLocalSpec(ctx, spec)
+	if err != nil {
+		return "", false, err
+	}
+	return v, v != spec, nil
+}
@@ -0,0 +1,131 @@
+package core
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestConstraintCheck(t *testing.T) {
+	tests := []struct {
+		constraint string
+		match      []string
+		noMatch    []string
+	}{
+		{"1.22", []string{"1.22", "1.22.0", "1.22.9"}, []string{"1.21.9", "1.23.0", "1.2.2"}},
+		{"~1.22.3", []string{"1.22.3", "1.22.10"}, []string{"1.22.2", "1.23.0"}},
+		{"^1.21.5", []string{"1.21.5", "1.22.0", "1.99.0"}, []string{"1.21.4", "2.0.0"}},
+		{"1", []string{"1.0", "1.22.5", "1.99.1"}, []string{"2.0", "0.9"}},
+		{"~1", []string{"1.0", "1.25.0"}, []string{"2.0"}},
+		{"1.22.5", []string{"1.22.5", "go1.22.5"}, []string{"1.22.6", "1.22"}},
+		{"=1.21", []string{"1.21.0"}, []string{"1.21.1"}},
+		{"!=1.22.3", []string{"1.22.2", "1.22.4"}, []string{"1.22.3"}},
+		{">=1.21 <1.23", []string{"1.21.0", "1.22.9"}, []string{"1.20.14", "1.23.0"}},
+		{">1.21.2, <=1.22", []string{"1.21.3", "1.22.0"}, []string{"1.21.2", "1.22.1"}},
+		{"~1.20 || ^1.24", []string{"1.20.3", "1.24.0", "1.25.1"}, []string{"1.21.0", "1.23.5"}},
+		{"go1.22", []string{"1.22.1"}, []string{"1.21.1"}},
+	}
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Errorf("ParseConstraint(%q): %v", tt.constraint, err)
+			continue
+		}
+		for _, s := range tt.match {
+			if v, _ := ParseVersion(s); !c.Check(v) {
+				t.Errorf("%q should match %s", tt.constraint, s)
+			}
+		}
+		for _, s := range tt.noMatch {
+			if v, _ := ParseVersion(s); c.Check(v) {
+				t.Errorf("%q should not match %s", tt.constraint, s)
+			}
+		}
+	}
+}
+
+func TestParseConstraintInvalid(t *testing.T) {
+	for _, s := range []string{"", "||", ">=", ">=1.21 ||", "~x", "1.22.5rc1", "newest"} {
+		if _, err := ParseConstraint(s); !errors.Is(err, ErrInvalidVersion) {
+			t.Errorf("ParseConstraint(%q) = %v, want ErrInvalidVersion", s, err)
+		}
+	}
+}
+
+func TestResolveSpec(t *testing.T) {
+	candidates := []string{"1.21.0", "1.21.13", "1.22.0", "1.22.5", "1.23rc1", "1.23beta1", "1.20.14", "2.0rc1"}
+	tests := []struct {
+		spec string
+		want string
+		// err is the expected error kind, nil for success
+		err error
+	}{
+		{spec: "latest", want: "1.22.5"},
+		{spec: "stable", want: "1.22.5"},
+		{spec: "LATEST", want: "1.22.5"},
+		{spec: "oldstable", want: "1.21.13"},
+		{spec: "1", want: "1.22.5"},
+		{spec: "^1.20", want: "1.22.5"},
+		{spec: "1.21", want: "1.21.13"},
+		{spec: "~1.21", want: "1.21.13"},
+		{spec: "<1.22", want: "1.21.13"},
+		{spec: ">=1.21 <1.22.5", want: "1.22.0"},
+		{spec: "1.20 || 1.21.0", want: "1.21.0"},
+		{spec: "1.22.0", want: "1.22.0"},
+		{spec: "go1.22.0", want: "1.22.0"},
+		// pre-releases are only selected by an exact version
+		{spec: "1.23", err: ErrVersionNotFound},
+		{spec: ">=1.23rc1", err: ErrVersionNotFound},
+		{spec: "1.23rc1", want: "1.23rc1"},
+		{spec: "2", err: ErrVersionNotFound},
+		{spec: "1.22.9", err: ErrVersionNotFound},
+		{spec: "bogus", err: ErrInvalidVersion},
+	}
+	for _, tt := range tests {
+		got, err := resolveSpec(tt.spec, candidates)
+		if tt.err != nil {
+			if !errors.Is(err, tt.err) {
+				t.Errorf("resolveSpec(%q) = %q, %v; want %v", tt.spec, got, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("resolveSpec(%q) = %q, %v; want %q", tt.spec, got, err, tt.want)
+		}
+	}
+}
+
+func TestResolveSpecOldstableNeedsTwoMinors(t *testing.T) {
+	if v, err := resolveSpec(KeywordOldstable, []string{"1.22.0", "1.22.5"}); !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("resolveSpec(oldstable) = %q, %v; want ErrVersionNotFound", v, err)
+	}
+}
+
+func TestRemoteCandidates(t *testing.T) {
+	archive := func(goos, goarch string) File {
+		return File{OS: goos, Arch: goarch, Kind: "archive"}
+	}
+	all := []DLVersion{
+		{Version: "go1.23rc1", Files: []File{archive("linux", "amd64")}},
+		{Version: "go1.22.5", Stable: true, Files: []File{archive("linux", "amd64"), archive("darwin", "arm64")}},
+		{Version: "go1.22.4", Stable: true, Files: []File{archive("darwin", "arm64")}},
+		{Version: "go1.22.3", Stable: true, Files: []File{{OS: "linux", Arch: "amd64", Kind: "installer"}}},
+		{Version: "go1.21.13", Stable: true, Files: []File{archive("linux", "arm64"), archive("linux", "amd64")}},
+		{Version: "bogus", Stable: true, Files: []File{archive("linux", "amd64")}},
+	}
+	tests := []struct {
+		spec, goos, goarch string
+		want               []string
+	}{
+		{"latest", "linux", "amd64", []string{"1.22.5", "1.21.13"}},
+		{"latest", "darwin", "arm64", []string{"1.22.5", "1.22.4"}},
+		{"1.23rc1", "linux", "amd64", []string{"1.23rc1", "1.22.5", "1.21.13"}},
+		{"latest", "windows", "amd64", nil},
+	}
+	for _, tt := range tests {
+		got := remoteCandidates(all, tt.spec, tt.goos, tt.goarch)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("remoteCandidates(%q, %s/%s) = %v, want %v", tt.spec, tt.goos, tt.goarch, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// CheckStatus is the outcome of a doctor check
+type CheckStatus string
+
+// Check statuses, from healthy to broken
+const (
+	CheckOK    CheckStatus = "ok"
+	CheckWarn  CheckStatus = "warn"
+	CheckError CheckStatus = "error"
+)
+
+// Check is the result of one environment diagnostic run by Doctor
+type Check struct {
+	Name   string
+	Status CheckStatus
+	Detail string
+	// Hint suggests how to fix the problem
+	Hint string
+
+	fix func(ctx context.Context) error
+	// settings are those Doctor ran with, so Fix repairs the same gvm directory
+	settings *Settings
+}
+
+// CanFix reports whether Fix can resolve the problem automatically
+func (c *Check) CanFix() bool {
+	return c.Status != CheckOK && c.fix != nil
+}
+
+// Fix applies the automatic fix for the problem. It uses the settings of the
+// Doctor call that returned c, not those of ctx.
+func (c *Check) Fix(ctx context.Context) error {
+	if !c.CanFix() {
+		return fmt.Errorf("%s cannot be fixed automatically", c.Name)
+	}
+	if c.settings != nil {
+		ctx = WithSettings(ctx, *c.settings)
+	}
+	return withLock(ctx, c.fix)
+}
+
+// Doctor diagnoses common environment problems: a missing or outdated
+// .gvmrc, a shell profile that does not source it, a dangling goroot symlink,
+// GOROOT or another go binary overriding gvm, missing shims and leftovers of
+// interrupted installs.
+func Doctor(ctx context.Context) ([]*Check, error) {
+	d, err := GvmDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s := settings(ctx)
+	if _, err := os.Stat(d); err != nil {
+		return []*Check{{
+			Name:     "gvm directory",
+			Status:   CheckError,
+			Detail:   fmt.Sprintf("%s does not exist", d),
+			Hint:     "run gvm init",
+			fix:      InitEnv,
+			settings: s,
+		}}, nil
+	}
+	checks := []*Check{
+		checkGvmrc(ctx),
+		checkShellRC(),
+		checkGorootLink(ctx, d),
+		checkGorootEnv(d),
+		checkGoOnPath(d),
+		checkShims(ctx),
+		checkStaleFiles(ctx, d),
+	}
+	for _, c := range checks {
+		c.settings = s
+	}
+	return checks, nil
+}
+
+func checkGvmrc(ctx context.Context) *Check {
+	c := &Check{Name: ".gvmrc", Status: CheckOK, fix: writeGvmrc, Hint: "run gvm doctor --fix to regenerate it"}
+	p, err := GvmrcPath(ctx)
+	if err != nil {
+		return c.fail(CheckError, err.Error())
+	}
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return c.fail(CheckError, fmt.Sprintf("%s is missing", p))
+	}
+	s := string(b)
+	var problems []string
+	if strings.Contains(s, "$GGOBIN") {
+		problems = append(problems, "adds $GGOBIN (a typo for $GOBIN) to PATH")
+	}
+	if strings.Contains(s, "export PATH=$PATH:$GOROOT/bin") {
+		problems = append(problems, "appends $GOROOT/bin to the end of PATH, so other Go installations win")
+	}
+	if len(problems) == 0 && s != gvmrcContent() {
+		problems = append(problems, "was written by an older gvm version")
+	}
+	if len(problems) > 0 {
+		return c.fail(CheckWarn, fmt.Sprintf("%s %s", p, strings.Join(problems, "; ")))
+	}
+	c.Detail = p
+	return c
+}
+
+func checkShellRC() *Check {
+	c := &Check{Name: "shell profile", Status: CheckOK}
+	rc, err := detectShellRC()
+	if err != nil {
+		return c.fail(CheckError, err.Error())
+	}
+	if rc == "" {
+		c.Hint = "add `source ~/.gvm/.gvmrc` to your shell profile"
+		return c.fail(CheckWarn, "no ~/.zshrc or ~/.bashrc found")
+	}
+	if !sourcesGvmrc(rc) {
+		c.Hint = "run gvm doctor --fix to add it"
+		c.fix = func(ctx context.Context) error {
+			_, err := ensureShellRC()
+			return err
+		}
+		return c.fail(CheckWarn, fmt.Sprintf("%s does not source ~/.gvm/.gvmrc", rc))
+	}
+	c.Detail = rc
+	return c
+}
+
+func checkGorootLink(ctx context.Context, d string) *Check {
+	c := &Check{Name: "goroot symlink", Status: CheckOK}
+	link := filepath.Join(d, "goroot")
+	fi, err := os.Lstat(link)
+	if os.IsNotExist(err) {
+		c.Hint = "run gvm use <version>"
+		return c.fail(CheckWarn, "no global default version is set")
+	}
+	if err != nil {
+		return c.fail(CheckError, err.Error())
+	}
+	if fi.Mode()&os.ModeSymlink == 0 {
+		c.Hint = fmt.Sprintf("remove %s and run gvm use <version>", link)
+		return c.fail(CheckError, fmt.Sprintf("%s is not a symlink", link))
+	}
+	target, _ := os.Readlink(link)
+	if _, err := os.Stat(link); err != nil {
+		installed, _ := ListLocal(ctx)
+		if len(installed) > 0 {
+			newest, err := resolveSpec(KeywordLatest, installed)
+			if err != nil {
+				newest = latestVersion(installed)
+			}
+			c.Hint = fmt.Sprintf("run gvm doctor --fix to switch to go%s", newest)
+			c.fix = func(ctx context.Context) error {
+				_, err := useVersion(ctx, newest)
+				return err
+			}
+		} else {
+			c.Hint = "run gvm doctor --fix to remove it, then install a version"
+			c.fix = func(ctx context.Context) error { return os.Remove(link) }
+		}
+		return c.fail(CheckError, fmt.Sprintf("%s points to %s, which does not exist", link, target))
+	}
+	c.Detail = target
+	return c
+}
+
+func checkGorootEnv(d string) *Check {
+	c := &Check{Name: "GOROOT", Status: CheckOK}
+	goroot := os.Getenv("GOROOT")
+	if goroot == "" {
+		c.Detail = "not set, the shims select the version"
+		return c
+	}
+	if !isUnder(d, goroot) {
+		c.Hint = "remove the GOROOT export from your shell profile, or source ~/.gvm/.gvmrc after it"
+		return c.fail(CheckWarn, fmt.Sprintf("GOROOT=%s points outside %s", goroot, d))
+	}
+	c.Detail = goroot
+	return c
+}
+
+func checkGoOnPath(d string) *Check {
+	c := &Check{Name: "go on PATH", Status: CheckOK}
+	p, err := exec.LookPath("go")
+	if err != nil {
+		c.Hint = "source ~/.gvm/.gvmrc in your shell profile and open a new shell"
+		return c.fail(CheckWarn, "no go command found on PATH")
+	}
+	if abs, err := filepath.Abs(p); err == nil {
+		p = abs
+	}
+	if !isUnder(d, p) {
+		c.Hint = fmt.Sprintf("make sure ~/.gvm/.gvmrc is sourced after anything adding %s to PATH", filepath.Dir(p))
+		return c.fail(CheckWarn, fmt.Sprintf("%s comes before the gvm shims on PATH", p))
+	}
+	c.Detail = p
+	return c
+}
+
+func checkShims(ctx context.Context) *Check {
+	c := &Check{Name: "shims", Status: CheckOK, fix: Rehash, Hint: "run gvm doctor --fix to recreate them"}
+	dir, err := ShimsDir(ctx)
+	if err != nil {
+		return c.fail(CheckError, err.Error())
+	}
+	var missing []string
+	for _, name := range ShimNames {
+		if runtime.GOOS == "windows" {
+			name += ".exe"
+		}
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return c.fail(CheckWarn, fmt.Sprintf("missing or broken in %s: %s", dir, strings.Join(missing, ", ")))
+	}
+	c.Detail = dir
+	return c
+}
+
+// checkStaleFiles looks for leftovers of interrupted installs and symlink swaps,
+// including extraction dirs in the system temp dir from older gvm versions
+func checkStaleFiles(ctx context.Context, d string) *Check {
+	c := &Check{Name: "stale temp files", Status: CheckOK}
+	stale := staleFiles(d)
+	if len(stale) == 0 {
+		c.Detail = "none"
+		return c
+	}
+	c.Hint = "run gvm doctor --fix to remove them"
+	c.fix = func(ctx context.Context) error {
+		// Fix runs under the lock, so no install is using these any more.
+		// CleanStaging first restores trees moved aside by an interrupted repair.
+		if _, err := CleanStaging(ctx); err != nil {
+			return err
+		}
+		for _, p := range staleFiles(d) {
+			if err := os.RemoveAll(p); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return c.fail(CheckWarn, fmt.Sprintf("%d found: %s", len(stale), strings.Join(stale, ", ")))
+}
+
+func staleFiles(d string) []string {
+	var stale []string
+	for _, pattern := range []string{
+		filepath.Join(os.TempDir(), "go-tgz-untar-*"),
+		filepath.Join(d, ".staging", "*"),
+		filepath.Join(d, "*.tmp-*"),
+	} {
+		matches, _ := filepath.Glob(pattern)
+		stale = append(stale, matches...)
+	}
+	return stale
+}
+
+func (c *Check) fail(status CheckStatus, detail string) *Check {
+	c.Status = status
+	c.Detail = detail
+	return c
+}
+
+// isUnder reports whether p is dir or inside it
+func isUnder(dir, p string) bool {
+	rel, err := filepath.Rel(dir, filepath.Clean(p))
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// partMeta records the validators of a partial download so it is only
+// resumed if the remote file did not change in between
+type partMeta struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// ifRange returns the validator to send in If-Range. Weak ETags cannot be
+// used for range requests, so Last-Modified is used instead.
+func (m partMeta) ifRange() string {
+	if m.ETag != "" && !strings.HasPrefix(m.ETag, "W/") {
+		return m.ETag
+	}
+	return m.LastModified
+}
+
+// downloadFile downloads url to dest.
+//
+// Data is written to dest+".part" and renamed to dest once complete. If a
+// previous attempt left a partial file, the download is resumed with a Range
+// request guarded by If-Range (ETag, or Last-Modified). Servers without range
+// support, or a changed remote file, restart the download from zero. The
+// partial file is also kept when ctx is canceled, so the next attempt resumes.
+// A connection reset while the body is read is retried (and resumed) as often
+// as the client retries failed requests.
+func downloadFile(ctx context.Context, url, dest string) error {
+	client, err := httpClient(ctx)
+	if err != nil {
+		return err
+	}
+	retries := httpRetries(client)
+	for attempt := 1; ; attempt++ {
+		retry, err := downloadAttempt(ctx, client, url, dest)
+		if !retry || attempt > retries {
+			return err
+		}
+		if backoff(ctx, attempt, retries, 0, "connection lost during download") != nil {
+			return interrupted(ctx)
+		}
+	}
+}
+
+// downloadAttempt makes one request for downloadFile. retry reports whether
+// the connection failed in a way worth retrying.
+func downloadAttempt(ctx context.Context, client *http.Client, url, dest string) (retry bool, err error) {
+	part := dest + ".part"
+	metaPath := part + ".json"
+
+	var offset int64
+	var meta partMeta
+	if fi, err := os.Stat(part); err == nil && fi.Size() > 0 {
+		if b, err := os.ReadFile(metaPath); err == nil && json.Unmarshal(b, &meta) == nil &&
+			meta.URL == url && meta.ifRange() != "" {
+			offset = fi.Size()
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return false, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		req.Header.Set("If-Range", meta.ifRange())
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, networkError(err)
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		if start, ok := contentRangeStart(resp.Header.Get("Content-Range")); !ok || start != offset {
+			return false, errorf(ErrNetwork, "download failed: unexpected Content-Range %q", resp.Header.Get("Content-Range"))
+		}
+		logf(ctx, "⏯️  Resuming download at %s\n", strings.TrimSpace(FormatSize(offset)))
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		// No range support or the file changed: start over
+		offset = 0
+		flags |= os.O_TRUNC
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The partial file is complete or larger than the remote file
+		if total, ok := contentRangeTotal(resp.Header.Get("Content-Range")); ok && total == offset {
+			_ = os.Remove(metaPath)
+			return false, os.Rename(part, dest)
+		}
+		_ = os.Remove(part)
+		_ = os.Remove(metaPath)
+		return downloadAttempt(ctx, client, url, dest)
+	case http.StatusNotFound:
+		return false, errorf(ErrVersionNotFound, "download failed: %s", resp.Status)
+	default:
+		return false, errorf(ErrNetwork, "download failed: %s", resp.Status)
+	}
+
+	meta = partMeta{URL: url, ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+	if err := writeJSONFile(metaPath, meta); err != nil {
+		return false, err
+	}
+
+	out, err := os.OpenFile(part, flags, 0o644)
+	if err != nil {
+		return false, err
+	}
+	defer out.Close()
+
+	cl := resp.ContentLength
+	if cl >= 0 {
+		cl += offset
+	}
+	progress := downloadProgress(ctx)
+	progress.Start(filepath.Base(dest), offset, cl)
+	written, rerr, err := copyProgress(out, resp.Body, offset, progress)
+	switch {
+	case err != nil:
+	case rerr != nil && interrupted(ctx) != nil:
+		err = interrupted(ctx)
+	case rerr != nil:
+		retry = retryable(rerr)
+		err = errorf(ErrNetwork, "download interrupted after %s (run the command again to resume): %w", strings.TrimSpace(FormatSize(written)), rerr)
+	case cl >= 0 && written != cl:
+		err = errorf(ErrNetwork, "download incomplete: got %d of %d bytes (run the command again to resume)", written, cl)
+	}
+	progress.Done(err)
+	if err != nil {
+		return retry, err
+	}
+	if err := out.Close(); err != nil {
+		return false, err
+	}
+	_ = os.Remove(metaPath)
+	return false, os.Rename(part, dest)
+}
+
+// contentRangeStart parses the first byte position of "bytes start-end/total"
+func contentRangeStart(h string) (int64, bool) {
+	h = strings.TrimPrefix(h, "bytes ")
+	i := strings.IndexByte(h, '-')
+	if i < 0 {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(h[:i], 10, 64)
+	return n, err == nil
+}
+
+// contentRangeTotal parses the complete length of "bytes */total" or "bytes start-end/total"
+func contentRangeTotal(h string) (int64, bool) {
+	i := strings.LastIndexByte(h, '/')
+	if i < 0 {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(h[i+1:], 10, 64)
+	return n, err == nil
+}
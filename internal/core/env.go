@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"errors"
 	"os"
 	"path/filepath"
@@ -15,7 +16,12 @@ func HomeDir() (string, error) {
 	return h, nil
 }
 
-func GvmDir() (string, error) {
+// GvmDir returns the gvm directory: the Root of the settings in ctx if set,
+// otherwise ~/.gvm
+func GvmDir(ctx context.Context) (string, error) {
+	if root := settings(ctx).Root; root != "" {
+		return root, nil
+	}
 	h, err := HomeDir()
 	if err != nil {
 		return "", err
@@ -23,53 +29,202 @@ func GvmDir() (string, error) {
 	return filepath.Join(h, ".gvm"), nil
 }
 
-func InitEnv() error {
-	d, err := GvmDir()
+func InitEnv(ctx context.Context) error {
+	d, err := GvmDir(ctx)
 	if err != nil {
 		return err
 	}
 	if err := os.MkdirAll(d, 0o755); err != nil {
 		return err
 	}
-	f := filepath.Join(d, ".gvmrc")
-	content := strings.Join([]string{
+	if err := Rehash(ctx); err != nil {
+		return err
+	}
+	if err := writeGvmrc(ctx); err != nil {
+		return err
+	}
+	if _, err := ensureShellRC(); err != nil {
+		return err
+	}
+	c := filepath.Join(d, "config.toml")
+	if _, err := os.Stat(c); errors.Is(err, os.ErrNotExist) {
+		if err := os.WriteFile(c, []byte(""), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// gvmrcContent returns the environment setup sourced from the shell profile.
+// $GOROOT/bin is prepended so other Go installations on PATH never win.
+func gvmrcContent() string {
+	return strings.Join([]string{
 		"export GOROOT=$HOME/.gvm/goroot",
-		"export PATH=$PATH:$GOROOT/bin",
+		"export PATH=$GOROOT/bin:$PATH",
 		"export GOPATH=$HOME/go",
 		"export GOBIN=$GOPATH/bin",
-		"export PATH=$PATH:$GGOBIN",
+		"export PATH=$PATH:$GOBIN",
 		"export GOPROXY=https://goproxy.cn,direct",
+		"export PATH=$HOME/.gvm/shims:$PATH",
 		"",
-	}, "\n")
-	if err := os.WriteFile(f, []byte(content), 0o644); err != nil {
+	}, "\n") + shellHook
+}
+
+// GvmrcPath returns the path of the environment file sourced by the shell profile
+func GvmrcPath(ctx context.Context) (string, error) {
+	d, err := GvmDir(ctx)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(d, ".gvmrc"), nil
+}
+
+func writeGvmrc(ctx context.Context) error {
+	f, err := GvmrcPath(ctx)
+	if err != nil {
 		return err
 	}
+	return os.WriteFile(f, []byte(gvmrcContent()), 0o644)
+}
+
+// ensureShellRC makes the detected shell profile source ~/.gvm/.gvmrc and
+// returns the profile path, or "" if no profile was found
+func ensureShellRC() (string, error) {
 	rc, err := detectShellRC()
+	if err != nil || rc == "" {
+		return rc, err
+	}
+	if sourcesGvmrc(rc) {
+		return rc, nil
+	}
+	line := "if [ -f \"$HOME/.gvm/.gvmrc\" ]; then\n    source \"$HOME/.gvm/.gvmrc\"\nfi\n"
+	f, err := os.OpenFile(rc, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
 	if err != nil {
-		return err
+		return rc, err
+	}
+	defer f.Close()
+	_, err = f.WriteString("\n# gvm shell setup\n" + line)
+	return rc, err
+}
+
+func sourcesGvmrc(rc string) bool {
+	b, _ := os.ReadFile(rc)
+	return strings.Contains(string(b), "/.gvm/.gvmrc")
+}
+
+// shellHook re-resolves the project version whenever the working directory
+// changes (chpwd in zsh, PROMPT_COMMAND in bash). It only runs gvm when the set
+// of project files above $PWD changed, and only exports GOROOT/PATH for the
+// current shell; the global goroot symlink is never touched.
+// Set GVM_AUTO_SWITCH=0 to disable it. A session version set by "gvm shell"
+// (which the gvm wrapper function evaluates) takes precedence over the hook.
+const shellHook = `
+# gvm auto-switch hook
+_gvm_hook() {
+    [ "${GVM_AUTO_SWITCH:-1}" = "0" ] && return
+    [ -n "$GVM_VERSION" ] && return
+    [ "$PWD" = "$_GVM_LAST_PWD" ] && return
+    _GVM_LAST_PWD="$PWD"
+    local d="$PWD" k="" f
+    while [ -n "$d" ]; do
+        for f in .go-version go.work go.mod; do
+            [ -f "$d/$f" ] && k="$k:$d/$f"
+        done
+        [ "$d" = "/" ] && break
+        d="${d%/*}"
+        [ -z "$d" ] && d="/"
+    done
+    [ "$k" = "$_GVM_LAST_KEY" ] && return
+    _GVM_LAST_KEY="$k"
+    command -v gvm >/dev/null 2>&1 || return
+    eval "$(command gvm resolve --export)"
+}
+gvm() {
+    if [ "$1" = "shell" ]; then
+        case " $* " in
+            *" -h "*|*" --help "*) command gvm "$@"; return ;;
+        esac
+        local out
+        out="$(command gvm "$@")" || return
+        eval "$out"
+        _GVM_LAST_PWD=""
+        _GVM_LAST_KEY=""
+    else
+        command gvm "$@"
+    fi
+}
+if [ -n "$ZSH_VERSION" ]; then
+    autoload -Uz add-zsh-hook
+    add-zsh-hook chpwd _gvm_hook
+    _gvm_hook
+elif [ -n "$BASH_VERSION" ]; then
+    case ";$PROMPT_COMMAND;" in
+        *";_gvm_hook;"*) ;;
+        *) PROMPT_COMMAND="_gvm_hook${PROMPT_COMMAND:+;$PROMPT_COMMAND}" ;;
+    esac
+fi
+`
+
+// ProjectEnv returns shell statements that select the project version for dir
+// in the current shell only. Outside a project (or when the project version is
+// not installed) GOROOT falls back to the global goroot symlink; in the latter
+// case the resolution error is returned alongside the statements.
+func ProjectEnv(ctx context.Context, dir string) ([]string, error) {
+	d, err := GvmDir(ctx)
+	if err != nil {
+		return nil, err
 	}
-	if rc != "" {
-		line := "if [ -f \"$HOME/.gvm/.gvmrc\" ]; then\n    source \"$HOME/.gvm/.gvmrc\"\nfi\n"
-		b, _ := os.ReadFile(rc)
-		s := string(b)
-		if !strings.Contains(s, "/.gvm/.gvmrc") {
-			f2, err := os.OpenFile(rc, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
-			if err != nil {
-				return err
-			}
-			defer f2.Close()
-			if _, err := f2.WriteString("\n# gvm shell setup\n" + line); err != nil {
-				return err
-			}
+	goroot := filepath.Join(d, "goroot")
+	pv, err := FindProjectVersion(dir)
+	if err != nil {
+		return nil, err
+	}
+	var rerr error
+	if pv != nil {
+		r, err := resolveInstalled(ctx, pv)
+		if err == nil {
+			goroot = filepath.Join(d, "go"+r.Version)
+		} else {
+			rerr = err
 		}
 	}
-	c := filepath.Join(d, "config.toml")
-	if _, err := os.Stat(c); errors.Is(err, os.ErrNotExist) {
-		if err := os.WriteFile(c, []byte(""), 0o644); err != nil {
-			return err
+	return ShellExports(ctx, goroot), rerr
+}
+
+// ShellExports returns POSIX shell statements pointing GOROOT and PATH at goroot.
+// PATH entries of other gvm-managed versions are dropped so switching
+// repeatedly does not grow PATH.
+func ShellExports(ctx context.Context, goroot string) []string {
+	d, _ := GvmDir(ctx)
+	bin := filepath.Join(goroot, "bin")
+	global := goroot == filepath.Join(d, "goroot")
+	parts := []string{}
+	if !global {
+		parts = append(parts, bin)
+	}
+	for _, p := range filepath.SplitList(os.Getenv("PATH")) {
+		if isVersionBinDir(d, p) || (!global && p == bin) {
+			continue
 		}
+		parts = append(parts, p)
 	}
-	return nil
+	return []string{
+		"export GOROOT=" + shellQuote(goroot),
+		"export PATH=" + shellQuote(strings.Join(parts, string(os.PathListSeparator))),
+	}
+}
+
+// isVersionBinDir reports whether p is the bin directory of a gvm-managed version
+func isVersionBinDir(gvmDir, p string) bool {
+	if gvmDir == "" || filepath.Base(p) != "bin" {
+		return false
+	}
+	v := filepath.Dir(p)
+	return filepath.Dir(v) == gvmDir && strings.HasPrefix(filepath.Base(v), "go") && filepath.Base(v) != "goroot"
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
 }
 
 func detectShellRC() (string, error) {
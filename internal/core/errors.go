@@ -0,0 +1,69 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Error kinds returned by core operations. Test for them with errors.Is; the
+// error message still carries the details (version, path, status...).
+var (
+	// ErrInvalidVersion reports a malformed version, constraint or version argument
+	ErrInvalidVersion = errors.New("invalid version")
+	// ErrVersionNotFound reports a version that is not published by the download source
+	ErrVersionNotFound = errors.New("version not found")
+	// ErrNotInstalled reports a version that is not installed locally
+	ErrNotInstalled = errors.New("version not installed")
+	// ErrAlreadyInstalled reports an install of a version that is already present
+	ErrAlreadyInstalled = errors.New("version already installed")
+	// ErrChecksumMismatch reports an archive whose SHA-256 does not match the expected one
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+	// ErrUnsafeArchive reports an archive entry rejected during extraction (see ExtractError)
+	ErrUnsafeArchive = errors.New("unsafe archive")
+	// ErrNetwork reports a failure talking to the download source or GitHub
+	ErrNetwork = errors.New("network error")
+	// ErrLocked reports that another gvm process held the lock for too long
+	ErrLocked = errors.New("gvm is locked by another process")
+	// ErrInterrupted reports an operation canceled through its context, e.g. by Ctrl-C
+	ErrInterrupted = errors.New("interrupted")
+)
+
+// kindError tags an error with one of the kinds above without changing its message
+type kindError struct {
+	kind error
+	err  error
+}
+
+func (e *kindError) Error() string {
+	return e.err.Error()
+}
+
+func (e *kindError) Unwrap() []error {
+	return []error{e.kind, e.err}
+}
+
+// errorf formats an error of the given kind. %w verbs keep wrapping their operand.
+func errorf(kind error, format string, args ...any) error {
+	return &kindError{kind: kind, err: fmt.Errorf(format, args...)}
+}
+
+// networkError marks err as a network failure, or as an interruption if the
+// request's context was canceled
+func networkError(err error) error {
+	if err == nil || errors.Is(err, ErrNetwork) || errors.Is(err, ErrInterrupted) {
+		return err
+	}
+	if errors.Is(err, context.Canceled) {
+		return &kindError{kind: ErrInterrupted, err: err}
+	}
+	return &kindError{kind: ErrNetwork, err: err}
+}
+
+// interrupted returns an ErrInterrupted error if ctx is done, nil otherwise
+func interrupted(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return errorf(ErrInterrupted, "interrupted: %w", err)
+	}
+	return nil
+}
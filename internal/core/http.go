@@ -0,0 +1,295 @@
+package core
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// HTTPConfig configures the HTTP client used for the version index, archive
+// downloads and self-update
+type HTTPConfig struct {
+	// Proxy is the proxy URL; empty means HTTPS_PROXY/HTTP_PROXY/NO_PROXY
+	Proxy string `json:"proxy,omitempty"`
+	// CAFile is a PEM bundle trusted in addition to the system roots
+	CAFile string `json:"ca_file,omitempty"`
+	// ClientCert is a PEM client certificate for mTLS
+	ClientCert string `json:"client_cert,omitempty"`
+	// ClientKey is the PEM key of ClientCert; empty means it is in the ClientCert file
+	ClientKey string `json:"client_key,omitempty"`
+	// Timeout limits connecting, waiting for response headers and waiting for
+	// more data of a response, e.g. "30s". It does not limit how long a
+	// download may take.
+	Timeout string `json:"timeout,omitempty"`
+	// Retries is how often failed requests are retried (default: 3)
+	Retries *int `json:"retries,omitempty"`
+}
+
+const (
+	// DefaultHTTPTimeout is used if http.timeout is not set
+	DefaultHTTPTimeout = 30 * time.Second
+	// DefaultHTTPRetries is used if http.retries is not set
+	DefaultHTTPRetries = 3
+)
+
+// timeout returns the configured timeout or DefaultHTTPTimeout
+func (c *HTTPConfig) timeout() (time.Duration, error) {
+	if c == nil || c.Timeout == "" {
+		return DefaultHTTPTimeout, nil
+	}
+	d, err := time.ParseDuration(c.Timeout)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid http.timeout %q: want a duration such as 30s", c.Timeout)
+	}
+	return d, nil
+}
+
+// retries returns the configured number of retries or DefaultHTTPRetries
+func (c *HTTPConfig) retries() int {
+	if c == nil || c.Retries == nil {
+		return DefaultHTTPRetries
+	}
+	return max(*c.Retries, 0)
+}
+
+// NewHTTPClient builds a client from cfg. A nil cfg uses the defaults.
+func NewHTTPClient(cfg *HTTPConfig) (*http.Client, error) {
+	if cfg == nil {
+		cfg = &HTTPConfig{}
+	}
+	timeout, err := cfg.timeout()
+	if err != nil {
+		return nil, err
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.DialContext = (&net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}).DialContext
+	t.TLSHandshakeTimeout = timeout
+	t.ResponseHeaderTimeout = timeout
+
+	if cfg.Proxy != "" {
+		u, err := url.Parse(cfg.Proxy)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid http.proxy %q", cfg.Proxy)
+		}
+		t.Proxy = http.ProxyURL(u)
+	}
+
+	if cfg.CAFile != "" || cfg.ClientCert != "" {
+		t.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	if cfg.CAFile != "" {
+		path, err := expandHome(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pem, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("http.ca_file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("http.ca_file: no PEM certificates in %s", path)
+		}
+		t.TLSClientConfig.RootCAs = pool
+	}
+	if cfg.ClientCert != "" {
+		certPath, err := expandHome(cfg.ClientCert)
+		if err != nil {
+			return nil, err
+		}
+		keyPath := certPath
+		if cfg.ClientKey != "" {
+			if keyPath, err = expandHome(cfg.ClientKey); err != nil {
+				return nil, err
+			}
+		}
+		cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("http.client_cert: %w", err)
+		}
+		t.TLSClientConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return &http.Client{Transport: &retryTransport{base: t, retries: cfg.retries(), timeout: timeout}}, nil
+}
+
+// sharedClient caches the client built from the config, so connections are
+// reused between the index fetch and the download
+var sharedClient struct {
+	sync.Mutex
+	key    httpClientKey
+	client *http.Client
+}
+
+// httpClientKey identifies an HTTPConfig by value
+type httpClientKey struct {
+	cfg     HTTPConfig // without Retries
+	retries int
+}
+
+// httpClient returns the HTTPClient of the settings in ctx, or the client
+// built from the http section of the config
+func httpClient(ctx context.Context) (*http.Client, error) {
+	if c := settings(ctx).HTTPClient; c != nil {
+		return c, nil
+	}
+	cfg, err := LoadConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key := httpClientKey{retries: cfg.HTTP.retries()}
+	if cfg.HTTP != nil {
+		key.cfg = *cfg.HTTP
+		key.cfg.Retries = nil
+	}
+	sharedClient.Lock()
+	defer sharedClient.Unlock()
+	if sharedClient.client != nil && sharedClient.key == key {
+		return sharedClient.client, nil
+	}
+	c, err := NewHTTPClient(cfg.HTTP)
+	if err != nil {
+		return nil, err
+	}
+	sharedClient.key, sharedClient.client = key, c
+	return c, nil
+}
+
+// retryTransport retries requests failing with a 5xx or 429 status or a
+// reset connection, waiting exponentially longer between attempts. Response
+// bodies that stall for longer than timeout fail with os.ErrDeadlineExceeded.
+type retryTransport struct {
+	base    http.RoundTripper
+	retries int
+	timeout time.Duration
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		ctx, cancel := context.WithCancel(req.Context())
+		resp, err := t.base.RoundTrip(req.WithContext(ctx))
+		// requests with a body cannot be replayed
+		last := attempt > t.retries || (req.Body != nil && req.Body != http.NoBody)
+		if err == nil && (last || resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500) {
+			resp.Body = newIdleBody(resp.Body, t.timeout, cancel)
+			return resp, nil
+		}
+		if last || err != nil && !retryable(err) {
+			cancel()
+			return resp, err
+		}
+		reason := ""
+		var retryAfter time.Duration
+		if err != nil {
+			reason = err.Error()
+		} else {
+			reason = resp.Status
+			retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"))
+			resp.Body.Close()
+		}
+		cancel()
+		if err := backoff(req.Context(), attempt, t.retries, retryAfter, reason); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// idleBody cancels a response body that receives no data for timeout
+type idleBody struct {
+	io.ReadCloser
+	timeout time.Duration
+	timer   *time.Timer
+	cancel  context.CancelFunc
+	stalled atomic.Bool
+}
+
+func newIdleBody(body io.ReadCloser, timeout time.Duration, cancel context.CancelFunc) *idleBody {
+	b := &idleBody{ReadCloser: body, timeout: timeout, cancel: cancel}
+	b.timer = time.AfterFunc(timeout, func() {
+		b.stalled.Store(true)
+		cancel()
+	})
+	return b
+}
+
+func (b *idleBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err != nil && b.stalled.Load() {
+		return n, fmt.Errorf("no data received for %s: %w", b.timeout, os.ErrDeadlineExceeded)
+	}
+	if n > 0 {
+		b.timer.Reset(b.timeout)
+	}
+	return n, err
+}
+
+func (b *idleBody) Close() error {
+	b.timer.Stop()
+	b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// retryable reports whether err is a transient connection failure
+func retryable(err error) bool {
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, os.ErrDeadlineExceeded)
+}
+
+// backoff waits before retry number attempt: 1s, 2s, 4s... up to 30s, or as
+// long as the server asked with Retry-After (up to a minute)
+func backoff(ctx context.Context, attempt, retries int, retryAfter time.Duration, reason string) error {
+	d := 30 * time.Second
+	if attempt <= 5 {
+		d = time.Second << (attempt - 1)
+	}
+	if retryAfter > 0 {
+		d = min(retryAfter, time.Minute)
+	}
+	warnf(ctx, "⚠️  %s, retrying in %s (%d/%d)\n", reason, d, attempt, retries)
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// parseRetryAfter parses a Retry-After header given in seconds or as a date
+func parseRetryAfter(h string) time.Duration {
+	if h == "" {
+		return 0
+	}
+	if s, err := strconv.Atoi(h); err == nil {
+		return time.Duration(s) * time.Second
+	}
+	if t, err := http.ParseTime(h); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// httpRetries returns the number of retries of client, 0 if it does not retry
+func httpRetries(client *http.Client) int {
+	if t, ok := client.Transport.(*retryTransport); ok {
+		return t.retries
+	}
+	return 0
+}
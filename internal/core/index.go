@@ -0,0 +1,217 @@
+package core
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// IndexTTL is how long a cached version index is used without revalidation
+const IndexTTL = 10 * time.Minute
+
+// indexMeta is stored next to the cached index to revalidate it
+type indexMeta struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	FetchedAt    time.Time `json:"fetched_at"`
+}
+
+// indexMemo keeps decoded indexes by URL until they are IndexTTL old, so
+// one operation does not read and decode the cached file repeatedly
+var (
+	indexMu   sync.Mutex
+	indexMemo = map[string]memoIndex{}
+)
+
+type memoIndex struct {
+	all       []DLVersion
+	fetchedAt time.Time
+}
+
+// IsOffline reports whether offline mode is enabled
+func IsOffline(ctx context.Context) bool {
+	if settings(ctx).Offline {
+		return true
+	}
+	switch os.Getenv("GVM_OFFLINE") {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
+
+// FetchIndex returns the version index from the configured JSON source.
+// The index is cached under ~/.gvm/cache for IndexTTL and then revalidated
+// with ETag / If-Modified-Since; in offline mode only the cache is used.
+func FetchIndex(ctx context.Context) ([]DLVersion, error) {
+	url, err := GetDownloadSourceJSON(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	indexMu.Lock()
+	m, ok := indexMemo[url]
+	indexMu.Unlock()
+	if ok && time.Since(m.fetchedAt) < IndexTTL {
+		return m.all, nil
+	}
+	all, fetchedAt, err := loadIndex(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	indexMu.Lock()
+	indexMemo[url] = memoIndex{all: all, fetchedAt: fetchedAt}
+	indexMu.Unlock()
+	return all, nil
+}
+
+// loadIndex returns the index from the cache or url, and when it was last
+// fetched or revalidated
+func loadIndex(ctx context.Context, url string) ([]DLVersion, time.Time, error) {
+	cacheDir, err := CacheDir(ctx)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	sum := sha256.Sum256([]byte(url))
+	base := filepath.Join(cacheDir, "index-"+hex.EncodeToString(sum[:6]))
+	dataPath, metaPath := base+".json", base+".meta.json"
+
+	var meta indexMeta
+	cached, cerr := os.ReadFile(dataPath)
+	if cerr == nil {
+		if b, err := os.ReadFile(metaPath); err == nil {
+			_ = json.Unmarshal(b, &meta)
+		}
+	}
+
+	fromCache := func() ([]DLVersion, time.Time, error) {
+		all, err := decodeIndex(cached)
+		return all, meta.FetchedAt, err
+	}
+
+	if IsOffline(ctx) {
+		if cerr != nil {
+			return nil, time.Time{}, errorf(ErrNetwork, "offline mode: no cached version index for %s, run once without --offline", url)
+		}
+		return fromCache()
+	}
+	if cerr == nil && time.Since(meta.FetchedAt) < IndexTTL {
+		return fromCache()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	if cerr == nil {
+		if meta.ETag != "" {
+			req.Header.Set("If-None-Match", meta.ETag)
+		}
+		if meta.LastModified != "" {
+			req.Header.Set("If-Modified-Since", meta.LastModified)
+		}
+	}
+	client, err := httpClient(ctx)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		err = networkError(err)
+		if cerr == nil && !errors.Is(err, ErrInterrupted) {
+			warnf(ctx, "⚠️  Failed to refresh version index (%v), using cached copy\n", err)
+			return fromCache()
+		}
+		return nil, time.Time{}, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		if cerr != nil {
+			return nil, time.Time{}, errors.New("server returned 304 for an uncached version index")
+		}
+		meta.FetchedAt = time.Now()
+		_ = writeJSONFile(metaPath, meta)
+		return fromCache()
+	case http.StatusOK:
+	default:
+		return nil, time.Time{}, errorf(ErrNetwork, "远程查询失败: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, time.Time{}, networkError(err)
+	}
+	all, err := decodeIndex(data)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	now := time.Now()
+	if err := os.MkdirAll(cacheDir, 0o755); err == nil {
+		if err := writeFileAtomic(dataPath, data, 0o644); err == nil {
+			_ = writeJSONFile(metaPath, indexMeta{
+				URL:          url,
+				ETag:         resp.Header.Get("ETag"),
+				LastModified: resp.Header.Get("Last-Modified"),
+				FetchedAt:    now,
+			})
+		}
+	}
+	return all, now, nil
+}
+
+func decodeIndex(data []byte) ([]DLVersion, error) {
+	var all []DLVersion
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("invalid version index: %v", err)
+	}
+	return all, nil
+}
+
+// writeJSONFile atomically writes v as indented JSON to path
+func writeJSONFile(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0o644)
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames it
+// into place, so readers never observe a partially written file
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Chmod(tmp, perm); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// ageIndex makes the memoized and cached index for url older than IndexTTL
+func ageIndex(ctx context.Context, t *testing.T, url string) {
+	t.Helper()
+	old := time.Now().Add(-IndexTTL - time.Minute)
+	indexMu.Lock()
+	if m, ok := indexMemo[url]; ok {
+		m.fetchedAt = old
+		indexMemo[url] = m
+	}
+	indexMu.Unlock()
+
+	cacheDir, err := CacheDir(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metas, _ := filepath.Glob(filepath.Join(cacheDir, "index-*.meta.json"))
+	for _, p := range metas {
+		var meta indexMeta
+		b, err := os.ReadFile(p)
+		if err == nil {
+			err = json.Unmarshal(b, &meta)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		meta.FetchedAt = old
+		if err := writeJSONFile(p, meta); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFetchIndexHonoursTTL(t *testing.T) {
+	version, etag := "go1.22.5", `"v1"`
+	var conditional []string
+	src, ctx := newFakeSource(t, func(w http.ResponseWriter, r *http.Request) {
+		conditional = append(conditional, r.Header.Get("If-None-Match"))
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		_ = json.NewEncoder(w).Encode([]DLVersion{{Version: version, Stable: true}})
+	})
+	url := src.URL + "/index.json"
+
+	fetch := func(want string) {
+		t.Helper()
+		all, err := FetchIndex(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(all) != 1 || all[0].Version != want {
+			t.Fatalf("index = %+v, want %s", all, want)
+		}
+	}
+
+	fetch("go1.22.5")
+	fetch("go1.22.5")
+	if len(conditional) != 1 {
+		t.Fatalf("%d requests within IndexTTL, want 1", len(conditional))
+	}
+
+	// unchanged after IndexTTL: revalidated with the ETag
+	ageIndex(ctx, t, url)
+	fetch("go1.22.5")
+	if len(conditional) != 2 || conditional[1] != `"v1"` {
+		t.Fatalf("revalidation sent If-None-Match %q, want \"v1\"", conditional[1:])
+	}
+
+	// a new release after IndexTTL is seen without restarting the process
+	version, etag = "go1.22.6", `"v2"`
+	ageIndex(ctx, t, url)
+	fetch("go1.22.6")
+	fetch("go1.22.6")
+	if len(conditional) != 3 {
+		t.Fatalf("%d requests, want 3", len(conditional))
+	}
+}
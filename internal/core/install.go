@@ -1,193 +1,249 @@
 package core
 
 import (
-	"archive/tar"
-	"compress/gzip"
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"net/http"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
-	"time"
 )
 
-func InstallVersion(version string) error {
-	d, err := GvmDir()
-	if err != nil {
+// InstallVersion installs the version matching spec and returns the exact
+// version installed
+func InstallVersion(ctx context.Context, spec string) (string, error) {
+	var version string
+	err := withLock(ctx, func(ctx context.Context) error {
+		var err error
+		version, err = installVersion(ctx, spec)
 		return err
+	})
+	return version, err
+}
+
+func installVersion(ctx context.Context, version string) (string, error) {
+	d, err := GvmDir(ctx)
+	if err != nil {
+		return "", err
 	}
 	version = strings.TrimPrefix(version, "go")
+	if !isExactVersion(version) {
+		// Keyword, minor version or constraint: pick from the remote index
+		resolved, err := ResolveRemoteSpec(ctx, version)
+		if err != nil {
+			return "", err
+		}
+		logf(ctx, "🔎 %s -> go%s\n", version, resolved)
+		version = resolved
+	}
 	vdir := filepath.Join(d, "go"+version)
 	if _, err := os.Stat(vdir); err == nil {
-		return fmt.Errorf("version %s already installed", version)
+		return version, errorf(ErrAlreadyInstalled, "version %s already installed", version)
 	}
 
 	osys := runtime.GOOS
 	arch := runtime.GOARCH
 
 	// 1. 获取版本信息（URL 和 Checksum）
-	fmt.Printf("🔍 Searching for version %s ...\n", version)
-	fileInfo, err := getVersionInfo("go"+version, osys, arch)
+	logf(ctx, "🔍 Searching for version %s ...\n", version)
+	if v, err := ParseVersion(version); err == nil && v.IsPrerelease() {
+		logf(ctx, "⚠️  go%s is a pre-release version, not intended for production use\n", version)
+	}
+	fileInfo, err := getVersionInfo(ctx, "go"+version, osys, arch)
+	if err != nil && (!errors.Is(err, ErrVersionNotFound) || IsOffline(ctx)) {
+		// Only a version missing from a successfully fetched index falls
+		// back to an unverified download; never guess while offline
+		return "", err
+	}
 	if err != nil {
 		// Fallback: 如果 JSON 中找不到，尝试直接构造 URL（但不校验 checksum，或者给警告）
 		// 为了安全，这里我们先强制要求找到，或者打印警告
-		fmt.Printf("⚠️  Warning: Could not find version info in official JSON API: %v\n", err)
-		fmt.Println("⚠️  Proceeding with direct download (NO CHECKSUM VERIFICATION)")
+		logf(ctx, "⚠️  Warning: Could not find version info in official JSON API: %v\n", err)
+		logf(ctx, "⚠️  Proceeding with direct download (NO CHECKSUM VERIFICATION)\n")
 		// 构造默认 URL
 		fileInfo = &File{
-			Filename: fmt.Sprintf("go%s.%s-%s.tar.gz", version, osys, arch),
+			Filename: fmt.Sprintf("go%s.%s-%s%s", version, osys, arch, archiveExt(osys)),
 			SHA256:   "", // Empty means no verification
 		}
 		// URL 需手动构造，因为 fileInfo 只有文件名
 	}
 
-	sourceURL, err := GetDownloadSource()
+	sourceURL, err := GetDownloadSource(ctx)
 	if err != nil {
-		return err
+		return "", err
 	}
 	// Ensure the source URL ends with a slash
 	if !strings.HasSuffix(sourceURL, "/") {
 		sourceURL += "/"
 	}
 	downloadURL := sourceURL + fileInfo.Filename
-	tarPath := filepath.Join(d, fileInfo.Filename)
 
-	if err := os.MkdirAll(d, 0o755); err != nil {
-		return err
+	// 2. 下载文件并校验 Checksum（优先使用下载缓存）
+	tarPath, err := fetchArchive(ctx, downloadURL, fileInfo.Filename, fileInfo.SHA256)
+	if err != nil {
+		return "", err
+	}
+
+	// 3. 解压安装
+	if err := installArchive(ctx, tarPath, version, downloadURL); err != nil {
+		return "", err
 	}
 
-	fmt.Println("⬇️  Downloading go" + version + "...")
-	fmt.Println("🔗 Source:", downloadURL)
-	fmt.Println("📦 Dest:", tarPath)
+	logf(ctx, "🎉 Successfully installed go%s\n", version)
+	return version, nil
+}
 
-	// 2. 下载文件
-	if err := downloadFile(downloadURL, tarPath); err != nil {
+// InstallFromFile installs a Go archive from a local file, e.g. on hosts
+// without access to the download source. The version is detected from the
+// archive's go/VERSION file. If sum is set, the file's SHA-256 must match it.
+// It returns the installed version.
+func InstallFromFile(ctx context.Context, file, sum string) (string, error) {
+	var version string
+	err := withLock(ctx, func(ctx context.Context) error {
+		var err error
+		version, err = installFromFile(ctx, file, sum)
 		return err
-	}
+	})
+	return version, err
+}
 
-	// 3. 校验 Checksum
-	if fileInfo.SHA256 != "" {
-		fmt.Println("🛡️  Verifying checksum...")
-		if err := verifyChecksum(tarPath, fileInfo.SHA256); err != nil {
-			os.Remove(tarPath) // 删除损坏的文件
-			return fmt.Errorf("checksum verification failed: %v", err)
+func installFromFile(ctx context.Context, file, sum string) (string, error) {
+	if sum != "" {
+		logf(ctx, "🛡️  Verifying checksum...\n")
+		if err := verifyChecksum(file, strings.ToLower(sum)); err != nil {
+			return "", fmt.Errorf("checksum verification failed: %w", err)
 		}
-		fmt.Println("✅ Checksum verified")
-	} else {
-		fmt.Println("⚠️  Skipping checksum verification (not available)")
+		logf(ctx, "✅ Checksum verified\n")
 	}
-
-	// 4. 解压安装
-	fmt.Println("📦 Extracting...")
-	tdir, err := os.MkdirTemp("", "go-tgz-untar-*")
+	source, err := filepath.Abs(file)
 	if err != nil {
-		return err
+		return "", err
 	}
-	defer os.RemoveAll(tdir)
+	return installDetected(ctx, file, source)
+}
 
-	if err := untar(tarPath, tdir); err != nil {
+// InstallFromURL downloads a Go archive from an arbitrary URL into the download
+// cache and installs it. If sum is set, the download is verified against it.
+// It returns the installed version.
+func InstallFromURL(ctx context.Context, rawURL, sum string) (string, error) {
+	var version string
+	err := withLock(ctx, func(ctx context.Context) error {
+		var err error
+		version, err = installFromURL(ctx, rawURL, sum)
 		return err
-	}
+	})
+	return version, err
+}
 
-	src := filepath.Join(tdir, "go")
-	if _, err := os.Stat(src); err != nil {
-		return fmt.Errorf("package structure error: 'go' directory not found")
+func installFromURL(ctx context.Context, rawURL, sum string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
 	}
-
-	if err := os.Rename(src, vdir); err != nil {
-		return err
+	filename := path.Base(u.Path)
+	if filename == "/" || filename == "." {
+		return "", fmt.Errorf("cannot determine archive file name from %s", rawURL)
 	}
-
-	_ = os.Remove(tarPath)
-	fmt.Printf("🎉 Successfully installed go%s\n", version)
-	return nil
+	archive, err := fetchArchive(ctx, rawURL, filename, sum)
+	if err != nil {
+		return "", err
+	}
+	return installDetected(ctx, archive, rawURL)
 }
 
-func getVersionInfo(version, osys, arch string) (*File, error) {
-	// 查询包含所有版本的 JSON
-	sourceURL, err := GetDownloadSourceJSON()
+// installDetected installs an archive under the version recorded in its go/VERSION file
+func installDetected(ctx context.Context, archive, source string) (string, error) {
+	version, err := archiveVersion(archive)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	resp, err := http.Get(sourceURL)
+	logf(ctx, "🔍 Detected Go version: %s\n", version)
+	d, err := GvmDir(ctx)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("failed to fetch versions: %s", resp.Status)
+	if _, err := os.Stat(filepath.Join(d, "go"+version)); err == nil {
+		return version, errorf(ErrAlreadyInstalled, "version %s already installed", version)
 	}
-
-	var versions []DLVersion
-	if err := json.NewDecoder(resp.Body).Decode(&versions); err != nil {
-		return nil, err
+	if err := installArchive(ctx, archive, version, source); err != nil {
+		return "", err
 	}
-
-	for _, v := range versions {
-		if v.Version == version {
-			for _, f := range v.Files {
-				if f.OS == osys && f.Arch == arch && f.Kind == "archive" {
-					return &f, nil
-				}
-			}
-		}
-	}
-	return nil, fmt.Errorf("version not found in official list")
+	logf(ctx, "🎉 Successfully installed go%s\n", version)
+	return version, nil
 }
 
-func downloadFile(url, dest string) error {
-	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+// installArchive extracts a verified archive, moves its go directory into place
+// as version and records a receipt naming source
+func installArchive(ctx context.Context, archive, version, source string) error {
+	d, err := GvmDir(ctx)
 	if err != nil {
 		return err
 	}
-	defer out.Close()
+	if err := os.MkdirAll(d, 0o755); err != nil {
+		return err
+	}
 
-	resp, err := http.Get(url)
+	receipt, err := archiveReceipt(version, source, archive)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("download failed: %s", resp.Status)
+	logf(ctx, "📦 Extracting...\n")
+	cleanLeftoverStaging(ctx)
+	vdir := filepath.Join(d, "go"+version)
+	if err := stageArchive(ctx, archive, vdir); err != nil {
+		return err
+	}
+	if err := saveReceipt(ctx, receipt); err != nil {
+		logf(ctx, "⚠️  Failed to write install receipt: %v\n", err)
+	}
+	if err := recordManifest(ctx, version, vdir); err != nil {
+		logf(ctx, "⚠️  Failed to write file manifest: %v\n", err)
+	}
+	return nil
+}
+
+// parseVersionFile returns the version from the first line of a GOROOT VERSION file ("go1.22.5")
+func parseVersionFile(r io.Reader) (string, error) {
+	b, err := io.ReadAll(io.LimitReader(r, 4096))
+	if err != nil {
+		return "", err
+	}
+	line, _, _ := strings.Cut(string(b), "\n")
+	v, err := ParseVersion(line)
+	if err != nil {
+		return "", fmt.Errorf("unrecognized go/VERSION content: %q", line)
 	}
+	return v.String(), nil
+}
 
-	// Progress bar setup
-	cl := resp.ContentLength
-	start := time.Now()
-	var written int64
-	buf := make([]byte, 32*1024)
+func getVersionInfo(ctx context.Context, version, osys, arch string) (*File, error) {
+	// 查询包含所有版本的 JSON
+	versions, err := FetchIndex(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	for {
-		nr, er := resp.Body.Read(buf)
-		if nr > 0 {
-			nw, ew := out.Write(buf[0:nr])
-			if nw > 0 {
-				written += int64(nw)
-			}
-			if ew != nil {
-				return ew
-			}
-			if nr != nw {
-				return io.ErrShortWrite
-			}
-			printProgress(written, cl, start)
-		}
-		if er != nil {
-			if er != io.EOF {
-				return er
+	want, err := ParseVersion(version)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range versions {
+		if pv, err := ParseVersion(v.Version); err == nil && pv.Compare(want) == 0 {
+			for _, f := range v.Files {
+				if f.OS == osys && f.Arch == arch && f.Kind == "archive" {
+					return &f, nil
+				}
 			}
-			break
 		}
 	}
-	fmt.Println()
-	return nil
+	return nil, errorf(ErrVersionNotFound, "version not found in official list")
 }
 
 func verifyChecksum(path, expected string) error {
@@ -204,96 +260,13 @@ func verifyChecksum(path, expected string) error {
 
 	actual := hex.EncodeToString(h.Sum(nil))
 	if actual != expected {
-		return fmt.Errorf("expected %s, got %s", expected, actual)
+		return errorf(ErrChecksumMismatch, "expected %s, got %s", expected, actual)
 	}
 	return nil
 }
 
-func untar(tgz string, dest string) error {
-	f, err := os.Open(tgz)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-	gr, err := gzip.NewReader(f)
-	if err != nil {
-		return err
-	}
-	defer gr.Close()
-	tr := tar.NewReader(gr)
-	for {
-		hdr, err := tr.Next()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return err
-		}
-		p := filepath.Join(dest, hdr.Name)
-		switch hdr.Typeflag {
-		case tar.TypeDir:
-			if err := os.MkdirAll(p, os.FileMode(hdr.Mode)); err != nil {
-				return err
-			}
-		case tar.TypeReg:
-			if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
-				return err
-			}
-			of, err := os.OpenFile(p, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(hdr.Mode))
-			if err != nil {
-				return err
-			}
-			if _, err := io.Copy(of, tr); err != nil {
-				of.Close()
-				return err
-			}
-			of.Close()
-		case tar.TypeSymlink:
-			if err := os.Symlink(hdr.Linkname, p); err != nil {
-				return err
-			}
-		default:
-		}
-	}
-	return nil
-}
-
-func printProgress(written int64, total int64, start time.Time) {
-	pct := float64(0)
-	if total > 0 {
-		pct = float64(written) / float64(total)
-	}
-	speed := float64(written) / time.Since(start).Seconds()
-	eta := "--"
-	if speed > 0 && total > 0 {
-		rem := float64(total-written) / speed
-		eta = formatDuration(time.Duration(rem) * time.Second)
-	}
-	left := formatSize(written)
-	right := formatSize(total)
-	bar := progressBar(pct, 30)
-	etaPad := padRight(eta, 10)
-	fmt.Printf("\r  %s / %s %s  %5.2f%% %s/s %s", left, right, bar, pct*100, formatSize(int64(speed)), etaPad)
-}
-
-func progressBar(pct float64, width int) string {
-	if pct < 0 {
-		pct = 0
-	}
-	if pct > 1 {
-		pct = 1
-	}
-	filled := int(pct * float64(width))
-	if filled < 0 {
-		filled = 0
-	}
-	if filled > width-1 {
-		filled = width - 1
-	}
-	return "[" + strings.Repeat("-", filled) + ">" + strings.Repeat("-", width-filled-1) + "]"
-}
-
-func formatSize(b int64) string {
+// FormatSize formats a byte count in KB or MB
+func FormatSize(b int64) string {
 	kb := float64(b) / 1024
 	if kb < 1024 {
 		return fmt.Sprintf("%5.2f KB", kb)
@@ -301,25 +274,3 @@ func formatSize(b int64) string {
 	mb := kb / 1024
 	return fmt.Sprintf("%5.2f MB", mb)
 }
-
-func formatDuration(d time.Duration) string {
-	s := int(d.Seconds())
-	if s < 60 {
-		return fmt.Sprintf("%ds", s)
-	}
-	m := s / 60
-	s = s % 60
-	if m < 60 {
-		return fmt.Sprintf("%dm%ds", m, s)
-	}
-	h := m / 60
-	m = m % 60
-	return fmt.Sprintf("%dh%dm%ds", h, m, s)
-}
-
-func padRight(s string, width int) string {
-	if len(s) >= width {
-		return s
-	}
-	return s + strings.Repeat(" ", width-len(s))
-}
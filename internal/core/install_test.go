@@ -0,0 +1,126 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeSource serves a version index and records the requested paths
+type fakeSource struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests []string
+}
+
+// newFakeSource starts a download source serving index and returns a context
+// for a temporary gvm directory configured to use it
+func newFakeSource(t *testing.T, index http.HandlerFunc) (*fakeSource, context.Context) {
+	t.Helper()
+	s := &fakeSource{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.URL.Path)
+		s.mu.Unlock()
+		if r.URL.Path == "/index.json" {
+			index(w, r)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(s.Close)
+
+	_, root := fakeRoot(t)
+	retries := 0
+	client, err := NewHTTPClient(&HTTPConfig{Retries: &retries})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := WithSettings(context.Background(), Settings{Root: root, HTTPClient: client})
+	cfg := &Config{DownloadSource: s.URL + "/dl/", DownloadSourceJSON: s.URL + "/index.json"}
+	if err := saveConfig(ctx, cfg); err != nil {
+		t.Fatal(err)
+	}
+	return s, ctx
+}
+
+// downloads returns the requested archive paths
+func (s *fakeSource) downloads() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var dl []string
+	for _, p := range s.requests {
+		if strings.HasPrefix(p, "/dl/") {
+			dl = append(dl, p)
+		}
+	}
+	return dl
+}
+
+func TestInstallDoesNotFallBackOnIndexErrors(t *testing.T) {
+	src, ctx := newFakeSource(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	})
+	_, err := InstallVersion(ctx, "1.22.5")
+	if !errors.Is(err, ErrNetwork) {
+		t.Fatalf("err = %v, want ErrNetwork", err)
+	}
+	if dl := src.downloads(); len(dl) != 0 {
+		t.Fatalf("downloaded %v after the index failed", dl)
+	}
+}
+
+func TestInstallOfflineDoesNotDownload(t *testing.T) {
+	src, ctx := newFakeSource(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "[]")
+	})
+	t.Setenv("GVM_OFFLINE", "1")
+	_, err := InstallVersion(ctx, "1.22.5")
+	if !errors.Is(err, ErrNetwork) {
+		t.Fatalf("err = %v, want ErrNetwork", err)
+	}
+	if len(src.requests) != 0 {
+		t.Fatalf("offline install made requests: %v", src.requests)
+	}
+}
+
+func TestInstallOfflineNeedsCachedArchive(t *testing.T) {
+	file := fmt.Sprintf("go1.22.5.%s-%s%s", runtime.GOOS, runtime.GOARCH, archiveExt(runtime.GOOS))
+	src, ctx := newFakeSource(t, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]DLVersion{{Version: "go1.22.5", Stable: true, Files: []File{{
+			Filename: file, OS: runtime.GOOS, Arch: runtime.GOARCH, Kind: "archive",
+			SHA256: strings.Repeat("ab", 32),
+		}}}})
+	})
+	// cache the index, then go offline
+	if _, err := FetchIndex(ctx); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GVM_OFFLINE", "1")
+	_, err := InstallVersion(ctx, "1.22.5")
+	if !errors.Is(err, ErrNetwork) {
+		t.Fatalf("err = %v, want ErrNetwork", err)
+	}
+	if dl := src.downloads(); len(dl) != 0 {
+		t.Fatalf("offline install downloaded %v", dl)
+	}
+}
+
+func TestInstallFallsBackForVersionsMissingFromIndex(t *testing.T) {
+	src, ctx := newFakeSource(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "[]")
+	})
+	if _, err := InstallVersion(ctx, "1.22.5"); err == nil {
+		t.Fatal("install succeeded without an archive")
+	}
+	if dl := src.downloads(); len(dl) != 1 {
+		t.Fatalf("downloads = %v, want the unverified fallback download", dl)
+	}
+}
@@ -1,15 +1,21 @@
 package core
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 )
 
-func LinkVersion(path string) error {
+func LinkVersion(ctx context.Context, path string) error {
+	return withLock(ctx, func(ctx context.Context) error { return linkVersion(ctx, path) })
+}
+
+func linkVersion(ctx context.Context, path string) error {
 	absPath, err := filepath.Abs(path)
 	if err != nil {
 		return err
@@ -36,7 +42,7 @@ func LinkVersion(path string) error {
 	} else {
 		// 假设用户提供的是 go 二进制文件路径
 		// 尝试通过 go env GOROOT 获取真实路径
-		fmt.Printf("🔍 Resolving GOROOT from binary: %s\n", absPath)
+		logf(ctx, "🔍 Resolving GOROOT from binary: %s\n", absPath)
 		cmd := exec.Command(absPath, "env", "GOROOT")
 		out, err := cmd.Output()
 		if err != nil {
@@ -44,7 +50,7 @@ func LinkVersion(path string) error {
 		}
 		goroot = strings.TrimSpace(string(out))
 		goBin = absPath
-		fmt.Printf("✅ Found GOROOT: %s\n", goroot)
+		logf(ctx, "✅ Found GOROOT: %s\n", goroot)
 	}
 
 	// 2. 获取版本号
@@ -63,9 +69,9 @@ func LinkVersion(path string) error {
 		versionStr = strings.TrimPrefix(versionStr, "go")
 	}
 
-	fmt.Printf("🔍 Detected Go version: %s\n", versionStr)
+	logf(ctx, "🔍 Detected Go version: %s\n", versionStr)
 
-	d, err := GvmDir()
+	d, err := GvmDir(ctx)
 	if err != nil {
 		return err
 	}
@@ -80,21 +86,31 @@ func LinkVersion(path string) error {
 			// It's a symlink, check where it points
 			target, _ := os.Readlink(linkName)
 			if target == goroot {
-				fmt.Printf("⚠️  Version %s is already linked to %s\n", versionStr, goroot)
+				logf(ctx, "⚠️  Version %s is already linked to %s\n", versionStr, goroot)
 				return nil
 			}
-			fmt.Printf("⚠️  Updating existing link for %s\n", versionStr)
-			os.Remove(linkName)
+			logf(ctx, "⚠️  Updating existing link for %s\n", versionStr)
 		} else {
 			return fmt.Errorf("version %s already exists and is not a symlink (it might be a real installation)", versionStr)
 		}
 	}
 
-	if err := os.Symlink(goroot, linkName); err != nil {
+	if err := replaceSymlink(goroot, linkName); err != nil {
 		return fmt.Errorf("failed to create symlink: %v", err)
 	}
 
-	fmt.Printf("🔗 Linked %s -> %s\n", linkName, goroot)
-	fmt.Printf("🎉 You can now use it with: gvm use %s\n", versionStr)
+	receipt := &Receipt{
+		Version:     versionStr,
+		Source:      goroot,
+		InstalledAt: time.Now(),
+		GvmVersion:  GvmVersion,
+		Linked:      true,
+	}
+	if err := saveReceipt(ctx, receipt); err != nil {
+		logf(ctx, "⚠️  Failed to write install receipt: %v\n", err)
+	}
+
+	logf(ctx, "🔗 Linked %s -> %s\n", linkName, goroot)
+	logf(ctx, "🎉 You can now use it with: gvm use %s\n", versionStr)
 	return nil
 }
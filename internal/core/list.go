@@ -1,16 +1,16 @@
 package core
 
 import (
-    "encoding/json"
-    "fmt"
-    "net/http"
+    "context"
     "os"
-    "regexp"
+    "path/filepath"
     "strings"
 )
 
-func ListLocal() ([]string, error) {
-    d, err := GvmDir()
+// ListLocal returns the installed versions in ascending order.
+// Linked versions (symlinks created by "gvm link") are included.
+func ListLocal(ctx context.Context) ([]string, error) {
+    d, err := GvmDir(ctx)
     if err != nil {
         return nil, err
     }
@@ -18,42 +18,53 @@ func ListLocal() ([]string, error) {
     if err != nil {
         return nil, err
     }
-    re := regexp.MustCompile(`^go\d+\.\d+\.\d+$`)
     var vv []string
     for _, e := range es {
-        if e.IsDir() && re.MatchString(e.Name()) {
-            vv = append(vv, strings.TrimPrefix(e.Name(), "go"))
+        if !strings.HasPrefix(e.Name(), "go") {
+            continue
+        }
+        if _, err := ParseVersion(e.Name()); err != nil {
+            continue
         }
+        if fi, err := os.Stat(filepath.Join(d, e.Name())); err != nil || !fi.IsDir() {
+            continue
+        }
+        vv = append(vv, strings.TrimPrefix(e.Name(), "go"))
     }
+    sortVersions(vv)
     return vv, nil
 }
 
-func ListRemote(n int) ([]string, error) {
-    resp, err := http.Get("https://go.dev/dl/?mode=json&include=all")
+// ListRemote returns the n newest versions, newest first.
+// Betas and release candidates are only included if unstable is set.
+func ListRemote(ctx context.Context, n int, unstable bool) ([]string, error) {
+    all, err := FetchIndex(ctx)
     if err != nil {
         return nil, err
     }
-    defer resp.Body.Close()
-    if resp.StatusCode != 200 {
-        return nil, fmt.Errorf("远程查询失败: %s", resp.Status)
-    }
-    var all []DLVersion
-    dec := json.NewDecoder(resp.Body)
-    if err := dec.Decode(&all); err != nil {
-        return nil, err
-    }
-    re := regexp.MustCompile(`^go\d+\.\d+\.\d+$`)
     var vv []string
     for _, it := range all {
-        if !re.MatchString(it.Version) {
+        v, err := ParseVersion(it.Version)
+        if err != nil || (!unstable && isUnstable(it, v)) {
             continue
         }
-        v := strings.TrimPrefix(it.Version, "go")
-        vv = append(vv, v)
-        if len(vv) >= n {
-            break
-        }
+        vv = append(vv, v.String())
+    }
+    sortVersions(vv)
+    reverseStrings(vv)
+    if len(vv) > n {
+        vv = vv[:n]
     }
     return vv, nil
 }
 
+func reverseStrings(ss []string) {
+    for i, j := 0, len(ss)-1; i < j; i, j = i+1, j-1 {
+        ss[i], ss[j] = ss[j], ss[i]
+    }
+}
+
+// isUnstable reports whether a remote version is a beta or release candidate
+func isUnstable(dv DLVersion, v Version) bool {
+    return !dv.Stable || v.IsPrerelease()
+}
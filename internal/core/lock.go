@@ -0,0 +1,141 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultLockTimeout is how long a mutating command waits for another gvm
+// process to release the lock. It can be overridden with GVM_LOCK_TIMEOUT
+// (e.g. "30s") or Settings.LockTimeout.
+const DefaultLockTimeout = 5 * time.Minute
+
+// lockPollInterval is how often a waiting process retries the lock
+const lockPollInterval = 200 * time.Millisecond
+
+// LockPath returns the path of the advisory lock file guarding ~/.gvm
+func LockPath(ctx context.Context) (string, error) {
+	d, err := GvmDir(ctx)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(d, ".lock"), nil
+}
+
+// heldLockKey marks a context whose operation holds the lock file stored as its value
+type heldLockKey struct{}
+
+// withLock runs fn while holding the gvm lock, so concurrent gvm processes
+// (e.g. parallel CI jobs) never install, remove or switch versions at the same
+// time. The lock is re-entrant through the context passed to fn: nested
+// calls, such as an upgrade installing a version, reuse the lock already
+// held. Waiting for the lock stops when ctx is canceled.
+func withLock(ctx context.Context, fn func(ctx context.Context) error) error {
+	p, err := LockPath(ctx)
+	if err != nil {
+		return err
+	}
+	if held, _ := ctx.Value(heldLockKey{}).(string); held == p {
+		return fn(ctx)
+	}
+	f, err := lockFile(ctx, p)
+	if err != nil {
+		return err
+	}
+	defer releaseLock(f)
+	return fn(context.WithValue(ctx, heldLockKey{}, p))
+}
+
+// lockFile waits up to the lock timeout for an exclusive lock on the file p
+// and records the owner in it
+func lockFile(ctx context.Context, p string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(p, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := lockTimeout(ctx)
+	deadline := time.Now().Add(timeout)
+	waiting := false
+	for {
+		ok, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock %s: %v", p, err)
+		}
+		if ok {
+			break
+		}
+		owner := lockOwner(p)
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, errorf(ErrLocked, "timed out after %s waiting for lock held by %s (%s)", timeout, owner, p)
+		}
+		if !waiting {
+			warnf(ctx, "⏳ Waiting for lock held by %s (%s)...\n", owner, p)
+			waiting = true
+		}
+		select {
+		case <-ctx.Done():
+			f.Close()
+			return nil, interrupted(ctx)
+		case <-time.After(lockPollInterval):
+		}
+	}
+
+	// Record the owner for processes waiting on the lock. The host matters
+	// for locks in a cache directory shared between machines.
+	if err := f.Truncate(0); err == nil {
+		host, _ := os.Hostname()
+		_, _ = f.WriteAt([]byte(strconv.Itoa(os.Getpid())+" "+host+"\n"), 0)
+	}
+	return f, nil
+}
+
+// releaseLock unlocks and closes a file locked by lockFile
+func releaseLock(f *os.File) {
+	_ = f.Truncate(0)
+	unlockFile(f)
+	f.Close()
+}
+
+func lockTimeout(ctx context.Context) time.Duration {
+	if d := settings(ctx).LockTimeout; d > 0 {
+		return d
+	}
+	if s := os.Getenv("GVM_LOCK_TIMEOUT"); s != "" {
+		if d, err := time.ParseDuration(s); err == nil {
+			return d
+		}
+	}
+	return DefaultLockTimeout
+}
+
+// lockOwner describes the process recorded in the lock file, e.g. "pid 1234"
+// or "pid 1234 on build-7"
+func lockOwner(p string) string {
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return "another gvm process"
+	}
+	fields := strings.Fields(string(b))
+	if len(fields) == 0 {
+		return "another gvm process"
+	}
+	pid, err := strconv.Atoi(fields[0])
+	if err != nil || pid <= 0 {
+		return "another gvm process"
+	}
+	if host, _ := os.Hostname(); len(fields) > 1 && fields[1] != host {
+		return fmt.Sprintf("pid %d on %s", pid, fields[1])
+	}
+	return fmt.Sprintf("pid %d", pid)
+}
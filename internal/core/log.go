@@ -0,0 +1,60 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Logger receives the status messages printed while gvm works
+type Logger interface {
+	// Infof reports progress and status messages
+	Infof(format string, args ...any)
+	// Warnf reports problems that do not stop the operation
+	Warnf(format string, args ...any)
+}
+
+// StdLogger prints status messages to stdout and warnings to stderr
+type StdLogger struct{}
+
+func (StdLogger) Infof(format string, args ...any) {
+	fmt.Printf(format, args...)
+}
+
+func (StdLogger) Warnf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, format, args...)
+}
+
+// QuietLogger only prints warnings, e.g. for --quiet
+type QuietLogger struct{ StdLogger }
+
+func (QuietLogger) Infof(format string, args ...any) {}
+
+// jsonLogger writes messages as NDJSON events next to the progress events
+type jsonLogger struct {
+	enc *json.Encoder
+}
+
+// NewJSONLogger returns a Logger writing {"event":"message"} objects to w,
+// one per line, so a progress stream from NewJSONProgress stays parseable
+func NewJSONLogger(w io.Writer) Logger {
+	return jsonLogger{enc: json.NewEncoder(w)}
+}
+
+func (l jsonLogger) Infof(format string, args ...any) {
+	l.emit("info", format, args)
+}
+
+func (l jsonLogger) Warnf(format string, args ...any) {
+	l.emit("warn", format, args)
+}
+
+func (l jsonLogger) emit(level, format string, args []any) {
+	_ = l.enc.Encode(struct {
+		Event   string `json:"event"`
+		Level   string `json:"level"`
+		Message string `json:"message"`
+	}{"message", level, strings.TrimSpace(fmt.Sprintf(format, args...))})
+}
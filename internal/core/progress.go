@@ -0,0 +1,277 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Progress receives the progress of a download
+type Progress interface {
+	// Start is called when data starts to arrive. offset is the number of
+	// bytes resumed from a previous attempt; total is -1 if unknown.
+	Start(name string, offset, total int64)
+	// Update reports the number of bytes downloaded so far, including offset
+	Update(written int64)
+	// Done is called when the download ends, with the error if it failed
+	Done(err error)
+}
+
+// NopProgress discards progress, e.g. for --quiet
+var NopProgress Progress = nopProgress{}
+
+type nopProgress struct{}
+
+func (nopProgress) Start(string, int64, int64) {}
+func (nopProgress) Update(int64)               {}
+func (nopProgress) Done(error)                 {}
+
+// AutoProgress returns a progress bar if f is a terminal and periodic log
+// lines otherwise, so CI logs are not flooded with redraws. The bar is
+// colored unless NO_COLOR is set.
+func AutoProgress(f *os.File) Progress {
+	if IsTerminal(f) {
+		return NewBarProgress(f, os.Getenv("NO_COLOR") == "")
+	}
+	return NewLineProgress(f, 5*time.Second)
+}
+
+// IsTerminal reports whether f is a character device such as a terminal
+func IsTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// transfer tracks the state shared by the progress implementations
+type transfer struct {
+	name          string
+	offset, total int64
+	written       int64
+	start, last   time.Time
+}
+
+func (t *transfer) begin(name string, offset, total int64) {
+	*t = transfer{name: name, offset: offset, total: total, written: offset, start: time.Now()}
+}
+
+// due reports whether interval has passed since the last report
+func (t *transfer) due(interval time.Duration) bool {
+	now := time.Now()
+	if now.Sub(t.last) < interval {
+		return false
+	}
+	t.last = now
+	return true
+}
+
+// speed returns the transfer rate in bytes per second. Resumed bytes are excluded.
+func (t *transfer) speed() float64 {
+	return float64(t.written-t.offset) / time.Since(t.start).Seconds()
+}
+
+func (t *transfer) percent() float64 {
+	if t.total <= 0 {
+		return 0
+	}
+	return float64(t.written) / float64(t.total)
+}
+
+// barProgress redraws a progress bar on a single terminal line
+type barProgress struct {
+	w     io.Writer
+	color bool
+	t     transfer
+}
+
+// NewBarProgress returns a progress bar redrawn in place on w with \r
+func NewBarProgress(w io.Writer, color bool) Progress {
+	return &barProgress{w: w, color: color}
+}
+
+func (p *barProgress) Start(name string, offset, total int64) {
+	p.t.begin(name, offset, total)
+}
+
+func (p *barProgress) Update(written int64) {
+	p.t.written = written
+	if written == p.t.total || p.t.due(100*time.Millisecond) {
+		p.draw()
+	}
+}
+
+func (p *barProgress) draw() {
+	speed := p.t.speed()
+	eta := "--"
+	if speed > 0 && p.t.total > 0 {
+		rem := float64(p.t.total-p.t.written) / speed
+		eta = formatDuration(time.Duration(rem) * time.Second)
+	}
+	bar := progressBar(p.t.percent(), 30)
+	if p.color {
+		bar = "\x1b[32m" + bar + "\x1b[0m"
+	}
+	fmt.Fprintf(p.w, "\r  %s / %s %s  %5.2f%% %s/s %s", FormatSize(p.t.written), FormatSize(p.t.total), bar, p.t.percent()*100, FormatSize(int64(speed)), padRight(eta, 10))
+}
+
+func (p *barProgress) Done(err error) {
+	if p.t.written != p.t.total {
+		// Update already drew the complete bar
+		p.draw()
+	}
+	fmt.Fprintln(p.w)
+}
+
+// lineProgress prints a line every interval, for logs and other non-terminals
+type lineProgress struct {
+	w        io.Writer
+	interval time.Duration
+	t        transfer
+}
+
+// NewLineProgress returns a progress reporter printing one line to w every interval
+func NewLineProgress(w io.Writer, interval time.Duration) Progress {
+	return &lineProgress{w: w, interval: interval}
+}
+
+func (p *lineProgress) Start(name string, offset, total int64) {
+	p.t.begin(name, offset, total)
+	p.t.last = p.t.start
+}
+
+func (p *lineProgress) Update(written int64) {
+	p.t.written = written
+	if p.t.due(p.interval) {
+		if p.t.total > 0 {
+			fmt.Fprintf(p.w, "  %s: %s / %s (%.0f%%), %s/s\n", p.t.name, strings.TrimSpace(FormatSize(written)), strings.TrimSpace(FormatSize(p.t.total)), p.t.percent()*100, strings.TrimSpace(FormatSize(int64(p.t.speed()))))
+		} else {
+			fmt.Fprintf(p.w, "  %s: %s, %s/s\n", p.t.name, strings.TrimSpace(FormatSize(written)), strings.TrimSpace(FormatSize(int64(p.t.speed()))))
+		}
+	}
+}
+
+func (p *lineProgress) Done(err error) {
+	if err != nil {
+		fmt.Fprintf(p.w, "  %s: failed after %s\n", p.t.name, strings.TrimSpace(FormatSize(p.t.written)))
+		return
+	}
+	fmt.Fprintf(p.w, "  %s: %s in %s\n", p.t.name, strings.TrimSpace(FormatSize(p.t.written)), formatDuration(time.Since(p.t.start)))
+}
+
+// jsonProgress writes newline-delimited JSON events for wrapper scripts
+type jsonProgress struct {
+	enc      *json.Encoder
+	interval time.Duration
+	t        transfer
+}
+
+// progressEvent is one line of the NDJSON progress stream
+type progressEvent struct {
+	Event string `json:"event"`
+	File  string `json:"file"`
+	Bytes int64  `json:"bytes"`
+	// Total is -1 if the server did not send a length
+	Total int64  `json:"total"`
+	Error string `json:"error,omitempty"`
+}
+
+// NewJSONProgress returns a progress reporter writing one JSON object per
+// line to w: a "download_start" event, "download" events at most every
+// interval and a final "download_done" event carrying the error, if any
+func NewJSONProgress(w io.Writer, interval time.Duration) Progress {
+	return &jsonProgress{enc: json.NewEncoder(w), interval: interval}
+}
+
+func (p *jsonProgress) Start(name string, offset, total int64) {
+	p.t.begin(name, offset, total)
+	p.emit("download_start", nil)
+}
+
+func (p *jsonProgress) Update(written int64) {
+	p.t.written = written
+	if p.t.due(p.interval) {
+		p.emit("download", nil)
+	}
+}
+
+func (p *jsonProgress) Done(err error) {
+	p.emit("download_done", err)
+}
+
+func (p *jsonProgress) emit(event string, err error) {
+	e := progressEvent{Event: event, File: p.t.name, Bytes: p.t.written, Total: p.t.total}
+	if err != nil {
+		e.Error = err.Error()
+	}
+	_ = p.enc.Encode(e)
+}
+
+// copyProgress copies r to w, reporting the running total (starting at
+// written) to p. It returns the new total and the first read or write error,
+// separately so callers can tell network failures from disk failures.
+func copyProgress(w io.Writer, r io.Reader, written int64, p Progress) (n int64, rerr, werr error) {
+	buf := make([]byte, 32*1024)
+	for {
+		nr, er := r.Read(buf)
+		if nr > 0 {
+			nw, ew := w.Write(buf[0:nr])
+			if nw > 0 {
+				written += int64(nw)
+			}
+			if ew == nil && nr != nw {
+				ew = io.ErrShortWrite
+			}
+			if ew != nil {
+				return written, nil, ew
+			}
+			p.Update(written)
+		}
+		if er == io.EOF {
+			return written, nil, nil
+		}
+		if er != nil {
+			return written, er, nil
+		}
+	}
+}
+
+func progressBar(pct float64, width int) string {
+	if pct < 0 {
+		pct = 0
+	}
+	if pct > 1 {
+		pct = 1
+	}
+	filled := int(pct * float64(width))
+	if filled < 0 {
+		filled = 0
+	}
+	if filled > width-1 {
+		filled = width - 1
+	}
+	return "[" + strings.Repeat("-", filled) + ">" + strings.Repeat("-", width-filled-1) + "]"
+}
+
+func formatDuration(d time.Duration) string {
+	s := int(d.Seconds())
+	if s < 60 {
+		return fmt.Sprintf("%ds", s)
+	}
+	m := s / 60
+	s = s % 60
+	if m < 60 {
+		return fmt.Sprintf("%dm%ds", m, s)
+	}
+	h := m / 60
+	m = m % 60
+	return fmt.Sprintf("%dh%dm%ds", h, m, s)
+}
+
+func padRight(s string, width int) string {
+	if len(s) >= width {
+		return s
+	}
+	return s + strings.Repeat(" ", width-len(s))
+}
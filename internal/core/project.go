@@ -0,0 +1,208 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Project files consulted when resolving the Go version for a directory.
+const (
+	GoVersionFile = ".go-version"
+	GoWorkFile    = "go.work"
+	GoModFile     = "go.mod"
+)
+
+// ProjectVersion is a Go version requested by a project file
+type ProjectVersion struct {
+	// Spec is the version as written in the file, without the "go" prefix (e.g. "1.22" or "1.22.5")
+	Spec string
+	// File is the path of the file that selected the version
+	File string
+	// Directive is "toolchain" or "go" for go.mod/go.work, empty for .go-version
+	Directive string
+}
+
+// Resolution is a project version resolved to an installed version
+type Resolution struct {
+	ProjectVersion
+	// Version is the installed version selected for Spec (e.g. "1.22.5")
+	Version string
+}
+
+// FindProjectVersion walks up from dir looking for the file that selects the Go version.
+//
+// Precedence, from highest to lowest:
+//  1. the nearest .go-version
+//  2. the nearest go.work (toolchain directive, then go directive)
+//  3. the nearest go.mod (toolchain directive, then go directive)
+//
+// Files are compared directory by directory, so a go.mod in the current directory
+// wins over a .go-version in a parent. A go.mod never wins over an enclosing
+// go.work, matching the go command's workspace mode (GOWORK=off disables this).
+// Returns nil if no project file is found.
+func FindProjectVersion(dir string) (*ProjectVersion, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	var mod *ProjectVersion
+	for {
+		if mod == nil {
+			p := filepath.Join(dir, GoVersionFile)
+			if spec, err := readGoVersionFile(p); err == nil && spec != "" {
+				return &ProjectVersion{Spec: spec, File: p}, nil
+			}
+		}
+		if os.Getenv("GOWORK") != "off" {
+			p := filepath.Join(dir, GoWorkFile)
+			if pv, err := readGoDirectives(p); err == nil && pv != nil {
+				return pv, nil
+			}
+		}
+		if mod == nil {
+			p := filepath.Join(dir, GoModFile)
+			if pv, err := readGoDirectives(p); err == nil && pv != nil {
+				mod = pv
+				// Keep walking: an enclosing go.work takes precedence
+				if os.Getenv("GOWORK") == "off" {
+					return mod, nil
+				}
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return mod, nil
+}
+
+// ResolveProjectVersion finds the project version for dir and selects the
+// best matching installed version for it.
+func ResolveProjectVersion(ctx context.Context, dir string) (*Resolution, error) {
+	pv, err := FindProjectVersion(dir)
+	if err != nil {
+		return nil, err
+	}
+	if pv == nil {
+		return nil, fmt.Errorf("no %s, %s or %s found in %s or its parents", GoVersionFile, GoWorkFile, GoModFile, dir)
+	}
+	return resolveInstalled(ctx, pv)
+}
+
+// resolveInstalled selects the installed version for a project version
+func resolveInstalled(ctx context.Context, pv *ProjectVersion) (*Resolution, error) {
+	installed, err := ListLocal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	v, ok := selectInstalled(pv.Spec, installed)
+	if !ok && pv.Directive == "go" {
+		// The go directive is only a minimum: any newer toolchain can build the module
+		v, ok = newestInstalled(pv.Spec, installed)
+	}
+	if !ok {
+		return nil, errorf(ErrNotInstalled, "go%s required by %s is not installed (run: gvm install %s)", pv.Spec, pv.File, pv.Spec)
+	}
+	return &Resolution{ProjectVersion: *pv, Version: v}, nil
+}
+
+// selectInstalled picks the installed version that best satisfies spec.
+// An exact match wins; otherwise the highest installed patch of the same
+// minor version that is not lower than spec is selected.
+func selectInstalled(spec string, installed []string) (string, bool) {
+	for _, v := range installed {
+		if v == spec {
+			return v, true
+		}
+	}
+	want, err := ParseVersion(spec)
+	if err != nil {
+		return "", false
+	}
+	best := ""
+	for _, v := range installed {
+		pv, err := ParseVersion(v)
+		if err != nil || !pv.SameMinor(want) || pv.Compare(want) < 0 {
+			continue
+		}
+		if best == "" || compareVersions(v, best) > 0 {
+			best = v
+		}
+	}
+	return best, best != ""
+}
+
+// newestInstalled returns the highest installed version not lower than spec
+func newestInstalled(spec string, installed []string) (string, bool) {
+	best := ""
+	for _, v := range installed {
+		if compareVersions(v, spec) >= 0 && (best == "" || compareVersions(v, best) > 0) {
+			best = v
+		}
+	}
+	return best, best != ""
+}
+
+// readGoVersionFile returns the version written in a .go-version file
+func readGoVersionFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		return strings.TrimPrefix(line, "go"), nil
+	}
+	return "", nil
+}
+
+// readGoDirectives reads the toolchain and go directives of a go.mod or go.work file.
+// The toolchain directive is preferred; "toolchain default" is ignored.
+func readGoDirectives(path string) (*ProjectVersion, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var goVer, toolchain string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		switch fields[0] {
+		case "go":
+			goVer = fields[1]
+		case "toolchain":
+			if fields[1] != "default" {
+				toolchain = strings.TrimPrefix(fields[1], "go")
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case toolchain != "":
+		return &ProjectVersion{Spec: toolchain, File: path, Directive: "toolchain"}, nil
+	case goVer != "":
+		return &ProjectVersion{Spec: goVer, File: path, Directive: "go"}, nil
+	}
+	return nil, nil
+}
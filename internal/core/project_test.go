@@ -0,0 +1,144 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeFiles creates files below root; names use forward slashes
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFindProjectVersion(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  map[string]string
+		dir    string
+		gowork string
+		// want is nil if no project file should be found
+		want *ProjectVersion
+	}{
+		{
+			name: "none",
+			dir:  "a",
+			files: map[string]string{
+				"a/main.go": "package main",
+			},
+		},
+		{
+			name:  "go-version",
+			dir:   "a",
+			files: map[string]string{"a/.go-version": "# pinned\n\ngo1.22.5\n"},
+			want:  &ProjectVersion{Spec: "1.22.5", File: "a/.go-version"},
+		},
+		{
+			name: "go-version wins over go.mod in the same directory",
+			dir:  "a",
+			files: map[string]string{
+				"a/.go-version": "1.21.3",
+				"a/go.mod":      "module a\n\ngo 1.22\n",
+			},
+			want: &ProjectVersion{Spec: "1.21.3", File: "a/.go-version"},
+		},
+		{
+			name: "toolchain wins over go line",
+			dir:  "a",
+			files: map[string]string{
+				"a/go.mod": "module a\n\ngo 1.21\n\ntoolchain go1.22.5 // pinned\n",
+			},
+			want: &ProjectVersion{Spec: "1.22.5", File: "a/go.mod", Directive: "toolchain"},
+		},
+		{
+			name: "toolchain default is ignored",
+			dir:  "a",
+			files: map[string]string{
+				"a/go.mod": "module a\n\ngo 1.21.0\ntoolchain default\n",
+			},
+			want: &ProjectVersion{Spec: "1.21.0", File: "a/go.mod", Directive: "go"},
+		},
+		{
+			name: "go.mod in a subdirectory wins over a parent go-version",
+			dir:  "a/b",
+			files: map[string]string{
+				"a/.go-version": "1.20",
+				"a/b/go.mod":    "module b\n\ngo 1.22\n",
+			},
+			want: &ProjectVersion{Spec: "1.22", File: "a/b/go.mod", Directive: "go"},
+		},
+		{
+			name: "enclosing go.work wins over go.mod",
+			dir:  "w/m/pkg",
+			files: map[string]string{
+				"w/go.work":  "go 1.23\n\nuse ./m\n",
+				"w/m/go.mod": "module m\n\ngo 1.21\ntoolchain go1.21.8\n",
+			},
+			want: &ProjectVersion{Spec: "1.23", File: "w/go.work", Directive: "go"},
+		},
+		{
+			name:   "GOWORK=off ignores go.work",
+			dir:    "w/m",
+			gowork: "off",
+			files: map[string]string{
+				"w/go.work":  "go 1.23\n",
+				"w/m/go.mod": "module m\n\ngo 1.21\n",
+			},
+			want: &ProjectVersion{Spec: "1.21", File: "w/m/go.mod", Directive: "go"},
+		},
+		{
+			name: "go-version above the module does not override it",
+			dir:  "a/m",
+			files: map[string]string{
+				"a/.go-version": "1.20",
+				"a/m/go.mod":    "module m\n\ngo 1.22\n",
+			},
+			want: &ProjectVersion{Spec: "1.22", File: "a/m/go.mod", Directive: "go"},
+		},
+		{
+			name: "go.mod without go line is skipped",
+			dir:  "a/b",
+			files: map[string]string{
+				"a/go.mod":   "module a\n\ngo 1.19\n",
+				"a/b/go.mod": "module b\n",
+			},
+			want: &ProjectVersion{Spec: "1.19", File: "a/go.mod", Directive: "go"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GOWORK", tt.gowork)
+			root := t.TempDir()
+			writeFiles(t, root, tt.files)
+			dir := filepath.Join(root, filepath.FromSlash(tt.dir))
+			if err := os.MkdirAll(dir, 0o755); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := FindProjectVersion(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.want == nil {
+				if got != nil {
+					t.Fatalf("got %+v, want none", *got)
+				}
+				return
+			}
+			want := *tt.want
+			want.File = filepath.Join(root, filepath.FromSlash(want.File))
+			if got == nil || *got != want {
+				t.Fatalf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Receipt records where an installed version came from
+type Receipt struct {
+	Version string `json:"version"`
+	// Source is the download URL, the local archive path or, for linked
+	// versions, the external GOROOT
+	Source string `json:"source"`
+	// SHA256 and Size describe the installed archive (empty for linked versions)
+	SHA256      string    `json:"sha256,omitempty"`
+	Size        int64     `json:"size,omitempty"`
+	InstalledAt time.Time `json:"installed_at"`
+	GvmVersion  string    `json:"gvm_version"`
+	Linked      bool      `json:"linked"`
+}
+
+// InstalledVersion describes an installed version for `gvm info`
+type InstalledVersion struct {
+	Version string `json:"version"`
+	Dir     string `json:"dir"`
+	// Target is the external GOROOT of a linked version
+	Target  string   `json:"target,omitempty"`
+	Current bool     `json:"current"`
+	Aliases []string `json:"aliases"`
+	// Receipt is nil for versions installed before receipts were recorded
+	Receipt *Receipt `json:"receipt,omitempty"`
+}
+
+// receiptsDir returns ~/.gvm/receipts. Receipts are kept outside the version
+// directories so linked SDKs are never written to.
+func receiptsDir(ctx context.Context) (string, error) {
+	d, err := GvmDir(ctx)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(d, "receipts"), nil
+}
+
+func receiptPath(ctx context.Context, version string) (string, error) {
+	dir, err := receiptsDir(ctx)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "go"+version+".json"), nil
+}
+
+// LoadReceipt returns the receipt of an installed version, or nil if none was recorded
+func LoadReceipt(ctx context.Context, version string) (*Receipt, error) {
+	p, err := receiptPath(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var r Receipt
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", p, err)
+	}
+	return &r, nil
+}
+
+func saveReceipt(ctx context.Context, r *Receipt) error {
+	p, err := receiptPath(ctx, r.Version)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	return writeJSONFile(p, r)
+}
+
+func removeReceipt(ctx context.Context, version string) error {
+	p, err := receiptPath(ctx, version)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// archiveReceipt builds the receipt for version installed from archive
+func archiveReceipt(version, source, archive string) (*Receipt, error) {
+	fi, err := os.Stat(archive)
+	if err != nil {
+		return nil, err
+	}
+	sum, err := fileSHA256(archive)
+	if err != nil {
+		return nil, err
+	}
+	return &Receipt{
+		Version:     version,
+		Source:      source,
+		SHA256:      sum,
+		Size:        fi.Size(),
+		InstalledAt: time.Now(),
+		GvmVersion:  GvmVersion,
+	}, nil
+}
+
+// VersionInfo describes the installed version matching spec, which may be an
+// exact version, an alias, a keyword or a constraint
+func VersionInfo(ctx context.Context, spec string) (*InstalledVersion, error) {
+	version, _, err := resolveInstalledVersion(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	return installedVersion(ctx, version)
+}
+
+func installedVersion(ctx context.Context, version string) (*InstalledVersion, error) {
+	d, err := GvmDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+	iv := &InstalledVersion{Version: version, Dir: filepath.Join(d, "go"+version)}
+	if fi, err := os.Lstat(iv.Dir); err != nil {
+		return nil, errorf(ErrNotInstalled, "version %s is not installed", version)
+	} else if fi.Mode()&os.ModeSymlink != 0 {
+		iv.Target, _ = os.Readlink(iv.Dir)
+	}
+	current, _ := CurrentVersion(ctx)
+	iv.Current = current == version
+	aliases, err := LoadAliases(ctx)
+	if err != nil {
+		return nil, err
+	}
+	iv.Aliases = append([]string{}, AliasesFor(aliases, version)...)
+	if iv.Receipt, err = LoadReceipt(ctx, version); err != nil {
+		return nil, err
+	}
+	return iv, nil
+}
+
+// ListInstalled returns details for every installed version, oldest first
+func ListInstalled(ctx context.Context) ([]*InstalledVersion, error) {
+	versions, err := ListLocal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := []*InstalledVersion{}
+	for _, v := range versions {
+		iv, err := installedVersion(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, iv)
+	}
+	return out, nil
+}
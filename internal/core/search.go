@@ -1,70 +1,44 @@
 package core
 
-import (
-    "encoding/json"
-    "fmt"
-    "net/http"
-    "os"
-    "regexp"
-    "strings"
-)
+import "context"
 
-func SearchRemote(prefix string, limit int) ([]string, error) {
-    prefix = strings.TrimPrefix(prefix, "go")
-    sourceURL, err := GetDownloadSourceJSON()
+// SearchRemote returns versions matching prefix (e.g. "1.22"), newest first.
+// Betas and release candidates are only included if prerelease is set.
+func SearchRemote(ctx context.Context, prefix string, limit int, prerelease bool) ([]string, error) {
+    all, err := FetchIndex(ctx)
     if err != nil {
         return nil, err
     }
-    resp, err := http.Get(sourceURL)
-    if err != nil {
-        return nil, err
-    }
-    defer resp.Body.Close()
-    if resp.StatusCode != 200 {
-        return nil, fmt.Errorf("远程查询失败: %s", resp.Status)
-    }
-    var all []DLVersion
-    dec := json.NewDecoder(resp.Body)
-    if err := dec.Decode(&all); err != nil {
-        return nil, err
-    }
-    re := regexp.MustCompile(`^go\d+\.\d+\.\d+$`)
     var vv []string
     for _, it := range all {
-        if !re.MatchString(it.Version) {
+        v, err := ParseVersion(it.Version)
+        if err != nil || (!prerelease && isUnstable(it, v)) {
             continue
         }
-        v := strings.TrimPrefix(it.Version, "go")
-        if strings.HasPrefix(v, prefix+".") {
-            vv = append(vv, v)
-            if limit > 0 && len(vv) >= limit {
-                break
-            }
+        if matchVersionPrefix(v, prefix) {
+            vv = append(vv, v.String())
         }
     }
+    sortVersions(vv)
+    reverseStrings(vv)
+    if limit > 0 && len(vv) > limit {
+        vv = vv[:limit]
+    }
     return vv, nil
 }
 
-func SearchLocal(prefix string) ([]string, error) {
-    prefix = strings.TrimPrefix(prefix, "go")
-    d, err := GvmDir()
+// SearchLocal returns installed versions matching prefix in ascending order
+func SearchLocal(ctx context.Context, prefix string) ([]string, error) {
+    installed, err := ListLocal(ctx)
     if err != nil {
         return nil, err
     }
-    es, err := os.ReadDir(d)
-    if err != nil {
-        return nil, err
-    }
-    re := regexp.MustCompile(`^go\d+\.\d+\.\d+$`)
     var vv []string
-    for _, e := range es {
-        if e.IsDir() && re.MatchString(e.Name()) {
-            v := strings.TrimPrefix(e.Name(), "go")
-            if strings.HasPrefix(v, prefix+".") {
-                vv = append(vv, v)
-            }
+    for _, s := range installed {
+        v, err := ParseVersion(s)
+        if err == nil && matchVersionPrefix(v, prefix) {
+            vv = append(vv, s)
         }
     }
     return vv, nil
 }
-
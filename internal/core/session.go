@@ -0,0 +1,85 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SessionEnvVar holds the version selected for the current shell by "gvm shell"
+const SessionEnvVar = "GVM_VERSION"
+
+// VersionSource describes where the active version was selected
+type VersionSource string
+
+const (
+	// SourceSession means the version was selected with "gvm shell" (GVM_VERSION)
+	SourceSession VersionSource = "session"
+	// SourceProject means the version was selected by a project file
+	SourceProject VersionSource = "project"
+	// SourceGlobal means the global default set by "gvm use" applies
+	SourceGlobal VersionSource = "global"
+)
+
+// Active is the version that applies to a directory
+type Active struct {
+	Version string
+	Source  VersionSource
+	// Project is the project file that selected the version, set for SourceProject
+	Project *ProjectVersion
+}
+
+// ActiveVersion returns the version that applies in dir: the GVM_VERSION
+// environment variable, then the project version, then the global default.
+func ActiveVersion(ctx context.Context, dir string) (*Active, error) {
+	if v := os.Getenv(SessionEnvVar); v != "" {
+		return &Active{Version: strings.TrimPrefix(v, "go"), Source: SourceSession}, nil
+	}
+	pv, err := FindProjectVersion(dir)
+	if err != nil {
+		return nil, err
+	}
+	if pv != nil {
+		r, err := resolveInstalled(ctx, pv)
+		if err != nil {
+			return nil, err
+		}
+		return &Active{Version: r.Version, Source: SourceProject, Project: pv}, nil
+	}
+	v, err := CurrentVersion(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("no Go version selected, run: gvm use <version>")
+	}
+	return &Active{Version: v, Source: SourceGlobal}, nil
+}
+
+// SessionEnv returns shell statements that select version for the current shell only
+func SessionEnv(ctx context.Context, version string) ([]string, error) {
+	d, err := GvmDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+	spec := version
+	version, resolved, err := resolveInstalledVersion(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	var lines []string
+	if resolved {
+		lines = append(lines, fmt.Sprintf("# %s -> go%s", spec, version))
+	}
+	lines = append(lines, ShellExports(ctx, filepath.Join(d, "go"+version))...)
+	return append(lines, "export "+SessionEnvVar+"="+shellQuote(version)), nil
+}
+
+// UnsetSessionEnv returns shell statements that drop the session version and
+// fall back to the project version for dir or the global default
+func UnsetSessionEnv(ctx context.Context, dir string) ([]string, error) {
+	lines, err := ProjectEnv(ctx, dir)
+	if lines == nil {
+		return nil, err
+	}
+	return append([]string{"unset " + SessionEnvVar}, lines...), err
+}
@@ -0,0 +1,65 @@
+package core
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// Settings configure core operations. They travel in the context passed to
+// each operation (see WithSettings), so callers with different settings can
+// run at the same time. A context without settings uses the zero value.
+type Settings struct {
+	// Root is the gvm directory; empty means ~/.gvm
+	Root string
+	// HTTPClient is used for the version index, archive downloads and
+	// self-update; nil means the client built from the config (see HTTPConfig)
+	HTTPClient *http.Client
+	// Log receives status messages; nil discards them
+	Log Logger
+	// Progress reports the progress of archive and self-update downloads;
+	// nil discards it
+	Progress Progress
+	// Offline serves the version index and archives from the cache only.
+	// It is also enabled by GVM_OFFLINE=1.
+	Offline bool
+	// LockTimeout is how long a mutating operation waits for another gvm
+	// process to release the lock; zero means GVM_LOCK_TIMEOUT or
+	// DefaultLockTimeout
+	LockTimeout time.Duration
+}
+
+type settingsKey struct{}
+
+// WithSettings returns a copy of ctx carrying s
+func WithSettings(ctx context.Context, s Settings) context.Context {
+	return context.WithValue(ctx, settingsKey{}, &s)
+}
+
+// settings returns the settings carried by ctx
+func settings(ctx context.Context) *Settings {
+	if s, ok := ctx.Value(settingsKey{}).(*Settings); ok {
+		return s
+	}
+	return &Settings{}
+}
+
+func logf(ctx context.Context, format string, args ...any) {
+	if l := settings(ctx).Log; l != nil {
+		l.Infof(format, args...)
+	}
+}
+
+func warnf(ctx context.Context, format string, args ...any) {
+	if l := settings(ctx).Log; l != nil {
+		l.Warnf(format, args...)
+	}
+}
+
+// downloadProgress returns the progress reporter of ctx
+func downloadProgress(ctx context.Context) Progress {
+	if p := settings(ctx).Progress; p != nil {
+		return p
+	}
+	return NopProgress
+}
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// ShimNames are the commands dispatched through ~/.gvm/shims
+var ShimNames = []string{"go", "gofmt"}
+
+// ShimsDir returns the directory holding the shim executables
+func ShimsDir(ctx context.Context) (string, error) {
+	d, err := GvmDir(ctx)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(d, "shims"), nil
+}
+
+// ShimName reports whether argv0 invokes gvm through a shim and returns the shim name
+func ShimName(argv0 string) (string, bool) {
+	name := filepath.Base(argv0)
+	if runtime.GOOS == "windows" {
+		name = strings.TrimSuffix(strings.ToLower(name), ".exe")
+	}
+	for _, s := range ShimNames {
+		if name == s {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// Rehash (re)creates the shims, pointing each of them at the running gvm binary
+func Rehash(ctx context.Context) error {
+	dir, err := ShimsDir(ctx)
+	if err != nil {
+		return err
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if exe, err = filepath.EvalSymlinks(exe); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, name := range ShimNames {
+		if runtime.GOOS == "windows" {
+			name += ".exe"
+		}
+		if err := writeShim(exe, filepath.Join(dir, name)); err != nil {
+			return fmt.Errorf("failed to create shim %s: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// writeShim links dest to the gvm binary, falling back to a hard link or a
+// copy where symlinks are not available (e.g. Windows without developer mode)
+func writeShim(exe, dest string) error {
+	_ = os.Remove(dest)
+	if err := os.Symlink(exe, dest); err == nil {
+		return nil
+	}
+	if err := os.Link(exe, dest); err == nil {
+		return nil
+	}
+	in, err := os.Open(exe)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// ExecShim runs the real name binary of the active version with args.
+// On Unix it replaces the current process and only returns on error.
+func ExecShim(ctx context.Context, name string, args []string) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	active, err := ActiveVersion(ctx, wd)
+	if err != nil {
+		return err
+	}
+	version := active.Version
+	d, err := GvmDir(ctx)
+	if err != nil {
+		return err
+	}
+	goroot := filepath.Join(d, "go"+version)
+	bin := filepath.Join(goroot, "bin", name)
+	if runtime.GOOS == "windows" {
+		bin += ".exe"
+	}
+	if _, err := os.Stat(bin); err != nil {
+		return errorf(ErrNotInstalled, "go%s is not installed (%s not found)", version, bin)
+	}
+	env := []string{"GOROOT=" + goroot}
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, "GOROOT=") {
+			env = append(env, kv)
+		}
+	}
+	return execBinary(bin, append([]string{bin}, args...), env)
+}
@@ -0,0 +1,9 @@
+//go:build !windows
+
+package core
+
+import "syscall"
+
+func execBinary(bin string, argv []string, env []string) error {
+	return syscall.Exec(bin, argv, env)
+}
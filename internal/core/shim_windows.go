@@ -0,0 +1,19 @@
+//go:build windows
+
+package core
+
+import (
+	"os"
+	"os/exec"
+)
+
+// execBinary runs bin as a child process since Windows has no exec(2).
+// The child's exit status is returned as an *exec.ExitError.
+func execBinary(bin string, argv []string, env []string) error {
+	cmd := exec.Command(bin, argv[1:]...)
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
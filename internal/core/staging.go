@@ -0,0 +1,134 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// StagingDir returns the directory archives are extracted into before being
+// moved into place. It lives inside the gvm directory so the final rename
+// never crosses filesystems (e.g. when /tmp is a tmpfs).
+func StagingDir(ctx context.Context) (string, error) {
+	d, err := GvmDir(ctx)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(d, ".staging"), nil
+}
+
+// replacedSuffix marks the staging directory holding the previous tree of a
+// version while repairVersion re-extracts it
+const replacedSuffix = "-replaced-"
+
+// CleanStaging removes staging directories left behind by interrupted installs
+// and returns their paths. A tree moved aside by an interrupted repair is moved
+// back first if its version directory is missing, so it is never the copy lost.
+func CleanStaging(ctx context.Context) ([]string, error) {
+	d, err := GvmDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := StagingDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var removed []string
+	for _, e := range entries {
+		p := filepath.Join(dir, e.Name())
+		if name, _, ok := strings.Cut(e.Name(), replacedSuffix); ok {
+			vdir := filepath.Join(d, name)
+			if _, err := os.Lstat(vdir); os.IsNotExist(err) {
+				if err := os.Rename(filepath.Join(p, "go"), vdir); err == nil {
+					warnf(ctx, "⚠️  Restored %s from an interrupted repair\n", vdir)
+				}
+			}
+		}
+		if err := os.RemoveAll(p); err != nil {
+			return removed, err
+		}
+		removed = append(removed, p)
+	}
+	return removed, nil
+}
+
+// cleanLeftoverStaging removes staging directories of interrupted installs.
+// Callers must hold the gvm lock, so no other process is staging.
+func cleanLeftoverStaging(ctx context.Context) {
+	if removed, err := CleanStaging(ctx); err == nil && len(removed) > 0 {
+		logf(ctx, "🧹 Cleaned up %d leftover staging dir(s) from an interrupted install\n", len(removed))
+	}
+}
+
+// stageArchive extracts archive into a fresh staging directory, syncs it to
+// disk and renames its go directory to vdir. vdir either appears complete or
+// not at all, so an interrupted install is never listed as installed, and the
+// staging directory is removed when extraction fails or is canceled.
+func stageArchive(ctx context.Context, archive, vdir string) error {
+	dir, err := StagingDir(ctx)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tdir, err := os.MkdirTemp(dir, filepath.Base(vdir)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tdir)
+
+	if err := extractArchive(ctx, archive, tdir); err != nil {
+		return err
+	}
+
+	src := filepath.Join(tdir, "go")
+	if fi, err := os.Stat(src); err != nil || !fi.IsDir() {
+		return fmt.Errorf("package structure error: 'go' directory not found")
+	}
+	if err := syncTree(src); err != nil {
+		return err
+	}
+	if _, err := os.Lstat(vdir); err == nil {
+		return errorf(ErrAlreadyInstalled, "version %s already installed", strings.TrimPrefix(filepath.Base(vdir), "go"))
+	}
+	if err := os.Rename(src, vdir); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(vdir))
+}
+
+// syncTree flushes every regular file and directory below root to disk
+func syncTree(root string) error {
+	return filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		switch {
+		case d.IsDir():
+			return syncDir(p)
+		case d.Type().IsRegular():
+			return syncFile(p)
+		}
+		return nil
+	})
+}
+
+func syncFile(p string) error {
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return f.Sync()
+}
@@ -0,0 +1,8 @@
+//go:build !windows
+
+package core
+
+// syncDir flushes a directory's entries to disk, making renames into it durable
+func syncDir(dir string) error {
+	return syncFile(dir)
+}
@@ -1,11 +1,11 @@
 package core
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
-	"strconv"
 	"strings"
 )
 
@@ -16,10 +16,17 @@ type UninstallBatchSpec struct {
 	Keep      int    // Keep this many latest versions
 	All       bool   // Uninstall all versions
 	KeepCurrent bool // Keep the currently active version
+	Force     bool   // Also uninstall versions that aliases point at
 }
 
-func UninstallVersion(version string) error {
-	d, err := GvmDir()
+// UninstallVersion removes an installed version. Versions that an alias
+// points at are only removed with force, which also deletes those aliases.
+func UninstallVersion(ctx context.Context, version string, force bool) error {
+	return withLock(ctx, func(ctx context.Context) error { return uninstallVersion(ctx, version, force) })
+}
+
+func uninstallVersion(ctx context.Context, version string, force bool) error {
+	d, err := GvmDir(ctx)
 	if err != nil {
 		return err
 	}
@@ -30,30 +37,97 @@ func UninstallVersion(version string) error {
 
 	// Check if exists
 	if _, err := os.Stat(vdir); os.IsNotExist(err) {
-		return fmt.Errorf("version %s is not installed", version)
+		return errorf(ErrNotInstalled, "version %s is not installed", version)
+	}
+
+	// Check aliases
+	aliases, err := LoadAliases(ctx)
+	if err != nil {
+		return err
+	}
+	if names := AliasesFor(aliases, version); len(names) > 0 {
+		if !force {
+			return fmt.Errorf("version %s is referenced by alias %s, use --force to uninstall it anyway", version, strings.Join(names, ", "))
+		}
+		logf(ctx, "⚠️  Removing alias %s pointing to go%s\n", strings.Join(names, ", "), version)
+		for _, name := range names {
+			delete(aliases, name)
+		}
+		if err := saveAliases(ctx, aliases); err != nil {
+			return err
+		}
 	}
 
 	// Check if current
-	current, err := CurrentVersion()
+	current, err := CurrentVersion(ctx)
 	if err == nil && current == version {
-		fmt.Printf("⚠️  Warning: Version %s is currently in use.\n", version)
-		fmt.Println("   Unlinking current version...")
+		logf(ctx, "⚠️  Warning: Version %s is currently in use.\n", version)
+		logf(ctx, "   Unlinking current version...\n")
 		link := filepath.Join(d, "goroot")
 		_ = os.Remove(link)
 	}
 
-	fmt.Printf("🗑️  Uninstalling go%s...\n", version)
+	logf(ctx, "🗑️  Uninstalling go%s...\n", version)
 	if err := os.RemoveAll(vdir); err != nil {
 		return fmt.Errorf("failed to uninstall: %v", err)
 	}
+	if err := removeReceipt(ctx, version); err != nil {
+		logf(ctx, "⚠️  Failed to remove install receipt: %v\n", err)
+	}
+	if err := removeManifest(ctx, version); err != nil {
+		logf(ctx, "⚠️  Failed to remove file manifest: %v\n", err)
+	}
 
-	fmt.Printf("✅ Successfully uninstalled go%s\n", version)
+	logf(ctx, "✅ Successfully uninstalled go%s\n", version)
 	return nil
 }
 
-// UninstallBatch performs batch uninstall based on the specification
-func UninstallBatch(spec *UninstallBatchSpec) ([]string, error) {
-	versions, err := ListLocal()
+// UninstallFailure is a version a batch uninstall could not remove
+type UninstallFailure struct {
+	Version string `json:"version"`
+	Error   string `json:"error"`
+
+	err error
+}
+
+// BatchUninstallError is returned by UninstallBatch when some versions could
+// not be removed. The versions that were removed are still returned.
+type BatchUninstallError struct {
+	Failed []UninstallFailure
+}
+
+func (e *BatchUninstallError) Error() string {
+	msgs := make([]string, len(e.Failed))
+	for i, f := range e.Failed {
+		msgs[i] = fmt.Sprintf("go%s: %s", f.Version, f.Error)
+	}
+	return fmt.Sprintf("failed to uninstall %d version(s): %s", len(e.Failed), strings.Join(msgs, "; "))
+}
+
+// Unwrap returns the errors of the failed versions, so errors.Is sees their kinds
+func (e *BatchUninstallError) Unwrap() []error {
+	errs := make([]error, len(e.Failed))
+	for i, f := range e.Failed {
+		errs[i] = f.err
+	}
+	return errs
+}
+
+// UninstallBatch performs batch uninstall based on the specification. If some
+// versions fail, the others are still removed and the error is a
+// *BatchUninstallError.
+func UninstallBatch(ctx context.Context, spec *UninstallBatchSpec) ([]string, error) {
+	var uninstalled []string
+	err := withLock(ctx, func(ctx context.Context) error {
+		var err error
+		uninstalled, err = uninstallBatch(ctx, spec)
+		return err
+	})
+	return uninstalled, err
+}
+
+func uninstallBatch(ctx context.Context, spec *UninstallBatchSpec) ([]string, error) {
+	versions, err := ListLocal(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -65,7 +139,7 @@ func UninstallBatch(spec *UninstallBatchSpec) ([]string, error) {
 	// Get current version to protect it if needed
 	currentVersion := ""
 	if spec.KeepCurrent {
-		currentVersion, _ = CurrentVersion()
+		currentVersion, _ = CurrentVersion(ctx)
 	}
 
 	// Filter versions based on spec
@@ -126,55 +200,46 @@ func UninstallBatch(spec *UninstallBatchSpec) ([]string, error) {
 	}
 
 	// Show what will be uninstalled
-	fmt.Printf("将卸载以下版本:\n")
+	logf(ctx, "将卸载以下版本:\n")
 	for _, v := range toUninstall {
-		fmt.Printf("  - go%s\n", v)
+		logf(ctx, "  - go%s\n", v)
 	}
 
 	// Perform uninstall
 	var uninstalled []string
+	var failed []UninstallFailure
 	for _, v := range toUninstall {
-		if err := UninstallVersion(v); err != nil {
-			fmt.Printf("⚠️  Failed to uninstall go%s: %v\n", v, err)
+		if err := interrupted(ctx); err != nil {
+			return uninstalled, err
+		}
+		if err := UninstallVersion(ctx, v, spec.Force); err != nil {
+			failed = append(failed, UninstallFailure{Version: v, Error: err.Error(), err: err})
 		} else {
 			uninstalled = append(uninstalled, v)
 		}
 	}
 
+	if len(failed) > 0 {
+		return uninstalled, &BatchUninstallError{Failed: failed}
+	}
 	return uninstalled, nil
 }
 
-// compareVersions compares two version strings (e.g., "1.22.0" vs "1.21.0")
+// compareVersions compares two version strings (e.g., "1.22.0" vs "1.21.0", "1.22rc1")
+// using Go's version ordering. Unparseable versions sort before valid ones.
 // Returns: 1 if v1 > v2, -1 if v1 < v2, 0 if equal
 func compareVersions(v1, v2 string) int {
-	// Normalize versions (remove "go" prefix if present)
-	v1 = strings.TrimPrefix(v1, "go")
-	v2 = strings.TrimPrefix(v2, "go")
-
-	// Split by "."
-	parts1 := strings.Split(v1, ".")
-	parts2 := strings.Split(v2, ".")
-
-	// Compare each part
-	for i := 0; i < 3; i++ {
-		// Default to 0 if missing part
-		var n1, n2 int
-		if i < len(parts1) {
-			n1, _ = strconv.Atoi(parts1[i])
-		}
-		if i < len(parts2) {
-			n2, _ = strconv.Atoi(parts2[i])
-		}
-
-		if n1 > n2 {
-			return 1
-		}
-		if n1 < n2 {
-			return -1
-		}
+	p1, err1 := ParseVersion(v1)
+	p2, err2 := ParseVersion(v2)
+	switch {
+	case err1 != nil && err2 != nil:
+		return strings.Compare(v1, v2)
+	case err1 != nil:
+		return -1
+	case err2 != nil:
+		return 1
 	}
-
-	return 0
+	return p1.Compare(p2)
 }
 
 // matchVersionPattern checks if a version matches a pattern
@@ -0,0 +1,29 @@
+package core
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestUninstallBatchReportsFailures(t *testing.T) {
+	ctx, _ := fakeRoot(t, "1.21.0", "1.21.1", "1.22.0")
+	if _, err := SetAlias(ctx, "legacy", "1.21.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	uninstalled, err := UninstallBatch(ctx, &UninstallBatchSpec{Pattern: "1.21.*"})
+	if !slices.Equal(uninstalled, []string{"1.21.1"}) {
+		t.Errorf("uninstalled = %v, want [1.21.1]", uninstalled)
+	}
+	var batchErr *BatchUninstallError
+	if !errors.As(err, &batchErr) {
+		t.Fatalf("err = %v, want *BatchUninstallError", err)
+	}
+	if len(batchErr.Failed) != 1 || batchErr.Failed[0].Version != "1.21.0" || batchErr.Failed[0].Error == "" {
+		t.Errorf("failed = %+v, want go1.21.0 with its error", batchErr.Failed)
+	}
+	if installed, _ := ListLocal(ctx); !slices.Equal(installed, []string{"1.21.0", "1.22.0"}) {
+		t.Errorf("installed = %v, want [1.21.0 1.22.0]", installed)
+	}
+}
@@ -1,15 +1,14 @@
 package core
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
-	"time"
 )
 
 // GitHubRelease represents a GitHub release
@@ -34,9 +33,12 @@ const (
 )
 
 // LatestVersion fetches the latest version of gvm from GitHub releases
-func LatestVersion() (string, error) {
-	client := &http.Client{Timeout: 10 * time.Second}
-	req, err := http.NewRequest("GET", GitHubAPIURL, nil)
+func LatestVersion(ctx context.Context) (string, error) {
+	client, err := httpClient(ctx)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", GitHubAPIURL, nil)
 	if err != nil {
 		return "", err
 	}
@@ -44,12 +46,12 @@ func LatestVersion() (string, error) {
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", err
+		return "", networkError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("failed to fetch latest version: %s", resp.Status)
+		return "", errorf(ErrNetwork, "failed to fetch latest version: %s", resp.Status)
 	}
 
 	var release GitHubRelease
@@ -65,19 +67,19 @@ func LatestVersion() (string, error) {
 var GvmVersion = "dev"
 
 // SelfUpdate updates gvm to the latest version
-func SelfUpdate() error {
-	fmt.Printf("Current version: %s\n", GvmVersion)
+func SelfUpdate(ctx context.Context) error {
+	logf(ctx, "Current version: %s\n", GvmVersion)
 
 	// Get latest version from GitHub
-	latest, err := LatestVersion()
+	latest, err := LatestVersion(ctx)
 	if err != nil {
 		return err
 	}
-	fmt.Printf("Latest version: %s\n", latest)
+	logf(ctx, "Latest version: %s\n", latest)
 
 	// Check if already up to date
-	if GvmVersion != "dev" && GvmVersion == latest {
-		fmt.Println("Already up to date!")
+	if GvmVersion != "dev" && sameRelease(GvmVersion, latest) {
+		logf(ctx, "Already up to date!\n")
 		return nil
 	}
 
@@ -93,10 +95,10 @@ func SelfUpdate() error {
 		return err
 	}
 
-	fmt.Printf("Downloading %s...\n", assetName)
+	logf(ctx, "Downloading %s...\n", assetName)
 
 	// Get the download URL for the asset
-	downloadURL, err := getAssetDownloadURL(latest, assetName)
+	downloadURL, err := getAssetDownloadURL(ctx, latest, assetName)
 	if err != nil {
 		return err
 	}
@@ -105,16 +107,16 @@ func SelfUpdate() error {
 	tmpDir := os.TempDir()
 	tmpPath := filepath.Join(tmpDir, "gvm-new-"+assetName)
 
-	fmt.Printf("Downloading from %s...\n", downloadURL)
-	if err := downloadUpdateFile(downloadURL, tmpPath); err != nil {
+	logf(ctx, "Downloading from %s...\n", downloadURL)
+	defer os.Remove(tmpPath)
+	if err := downloadUpdateFile(ctx, downloadURL, tmpPath); err != nil {
 		return err
 	}
-	defer os.Remove(tmpPath)
 
-	fmt.Println("Download complete!")
+	logf(ctx, "Download complete!\n")
 
 	// Replace the old binary with the new one
-	fmt.Printf("Updating %s...\n", binPath)
+	logf(ctx, "Updating %s...\n", binPath)
 
 	// On Unix systems, we need to remove the old file first
 	// On Windows, we need to move the old file and then replace it
@@ -122,7 +124,7 @@ func SelfUpdate() error {
 		return err
 	}
 
-	fmt.Printf("Successfully updated to %s!\n", latest)
+	logf(ctx, "Successfully updated to %s!\n", latest)
 	return nil
 }
 
@@ -155,11 +157,14 @@ func getAssetName() (string, error) {
 	return fmt.Sprintf("gvm_%s_%s%s", osys, archStr, ext), nil
 }
 
-func getAssetDownloadURL(version, assetName string) (string, error) {
+func getAssetDownloadURL(ctx context.Context, version, assetName string) (string, error) {
 	// Get release info from GitHub
-	client := &http.Client{Timeout: 10 * time.Second}
+	client, err := httpClient(ctx)
+	if err != nil {
+		return "", err
+	}
 	url := fmt.Sprintf("https://api.github.com/repos/%s/releases/latest", GvmGitHubRepo)
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return "", err
 	}
@@ -167,12 +172,12 @@ func getAssetDownloadURL(version, assetName string) (string, error) {
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", err
+		return "", networkError(err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return "", fmt.Errorf("failed to fetch release info: %s", resp.Status)
+		return "", errorf(ErrNetwork, "failed to fetch release info: %s", resp.Status)
 	}
 
 	var release GitHubRelease
@@ -190,70 +195,43 @@ func getAssetDownloadURL(version, assetName string) (string, error) {
 	return "", fmt.Errorf("asset not found: %s", assetName)
 }
 
-func downloadUpdateFile(url, dest string) error {
+func downloadUpdateFile(ctx context.Context, url, dest string) error {
 	out, err := os.Create(dest)
 	if err != nil {
 		return err
 	}
 	defer out.Close()
 
-	resp, err := http.Get(url)
+	client, err := httpClient(ctx)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return err
 	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return networkError(err)
+	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return fmt.Errorf("download failed: %s", resp.Status)
-	}
-
-	// Show progress
-	size := resp.ContentLength
-	var written int64
-	buf := make([]byte, 32*1024)
-	start := time.Now()
-
-	for {
-		nr, er := resp.Body.Read(buf)
-		if nr > 0 {
-			nw, ew := out.Write(buf[0:nr])
-			if nw > 0 {
-				written += int64(nw)
-			}
-			if ew != nil {
-				return ew
-			}
-			if nr != nw {
-				return io.ErrShortWrite
-			}
-			printUpdateProgress(written, size, start)
-		}
-		if er != nil {
-			if er != io.EOF {
-				return er
-			}
-			break
-		}
+		return errorf(ErrNetwork, "download failed: %s", resp.Status)
 	}
-	fmt.Println()
-	return nil
-}
 
-func printUpdateProgress(written, total int64, start time.Time) {
-	pct := float64(0)
-	if total > 0 {
-		pct = float64(written) / float64(total)
+	progress := downloadProgress(ctx)
+	progress.Start(filepath.Base(dest), 0, resp.ContentLength)
+	_, rerr, err := copyProgress(out, resp.Body, 0, progress)
+	switch {
+	case err != nil:
+	case rerr != nil && interrupted(ctx) != nil:
+		err = interrupted(ctx)
+	case rerr != nil:
+		err = networkError(rerr)
 	}
-	speed := float64(written) / time.Since(start).Seconds()
-	eta := "--"
-	if speed > 0 && total > 0 {
-		rem := float64(total-written) / speed
-		eta = formatDuration(time.Duration(rem) * time.Second)
-	}
-	left := formatSize(written)
-	right := formatSize(total)
-	bar := progressBar(pct, 30)
-	fmt.Printf("\r  %s / %s %s  %5.2f%% %s/s %s", left, right, bar, pct*100, formatSize(int64(speed)), eta)
+	progress.Done(err)
+	return err
 }
 
 func replaceBinary(src, dest string) error {
@@ -288,8 +266,8 @@ func replaceBinary(src, dest string) error {
 }
 
 // CheckUpdate checks if there's a newer version available
-func CheckUpdate() (bool, string, error) {
-	latest, err := LatestVersion()
+func CheckUpdate(ctx context.Context) (bool, string, error) {
+	latest, err := LatestVersion(ctx)
 	if err != nil {
 		return false, "", err
 	}
@@ -298,9 +276,15 @@ func CheckUpdate() (bool, string, error) {
 		return true, latest, nil
 	}
 
-	if GvmVersion != latest {
+	if !sameRelease(GvmVersion, latest) {
 		return true, latest, nil
 	}
 
 	return false, latest, nil
 }
+
+// sameRelease reports whether a build version and a release tag name the same
+// release. Builds get "1.2.3" through ldflags while tags are "v1.2.3".
+func sameRelease(version, tag string) bool {
+	return strings.TrimPrefix(version, "v") == strings.TrimPrefix(tag, "v")
+}
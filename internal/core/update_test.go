@@ -0,0 +1,22 @@
+package core
+
+import "testing"
+
+func TestSameRelease(t *testing.T) {
+	tests := []struct {
+		version, tag string
+		want         bool
+	}{
+		{"1.2.3", "v1.2.3", true},
+		{"v1.2.3", "v1.2.3", true},
+		{"v1.2.3", "1.2.3", true},
+		{"1.2.3", "v1.2.4", false},
+		{"1.2.3", "v1.2.30", false},
+		{"dev", "v1.2.3", false},
+	}
+	for _, tt := range tests {
+		if got := sameRelease(tt.version, tt.tag); got != tt.want {
+			t.Errorf("sameRelease(%q, %q) = %v, want %v", tt.version, tt.tag, got, tt.want)
+		}
+	}
+}
@@ -1,37 +1,64 @@
 package core
 
 import (
+    "context"
+    "fmt"
     "os"
     "path/filepath"
     "strings"
 )
 
-func UseVersion(version string) error {
-    d, err := GvmDir()
-    if err != nil {
+// UseVersion makes the installed version matching spec the global default
+// and returns the exact version selected
+func UseVersion(ctx context.Context, spec string) (string, error) {
+    var version string
+    err := withLock(ctx, func(ctx context.Context) error {
+        var err error
+        version, err = useVersion(ctx, spec)
         return err
+    })
+    return version, err
+}
+
+func useVersion(ctx context.Context, spec string) (string, error) {
+    d, err := GvmDir(ctx)
+    if err != nil {
+        return "", err
     }
-    if strings.HasPrefix(version, "go") {
-        version = strings.TrimPrefix(version, "go")
+    version, resolved, err := resolveInstalledVersion(ctx, spec)
+    if err != nil {
+        return "", err
+    }
+    if resolved {
+        logf(ctx, "🔎 %s -> go%s\n", spec, version)
     }
     vdir := filepath.Join(d, "go"+version)
-    if _, err := os.Stat(vdir); err != nil {
+    return version, replaceSymlink(vdir, filepath.Join(d, "goroot"))
+}
+
+// replaceSymlink points link at target. The new link is created next to link
+// and renamed over it, so link always exists and points at a valid version.
+func replaceSymlink(target, link string) error {
+    tmp := fmt.Sprintf("%s.tmp-%d", link, os.Getpid())
+    _ = os.Remove(tmp)
+    if err := os.Symlink(target, tmp); err != nil {
         return err
     }
-    link := filepath.Join(d, "goroot")
-    if fi, err := os.Lstat(link); err == nil && fi.Mode()&os.ModeSymlink != 0 {
-        _ = os.Remove(link)
-    } else if err == nil {
-        _ = os.Remove(link)
-    }
-    if err := os.Symlink(vdir, link); err != nil {
-        return err
+    if err := os.Rename(tmp, link); err != nil {
+        // Windows cannot rename over an existing directory link
+        if rerr := os.Remove(link); rerr == nil {
+            err = os.Rename(tmp, link)
+        }
+        if err != nil {
+            os.Remove(tmp)
+            return err
+        }
     }
     return nil
 }
 
-func CurrentVersion() (string, error) {
-    d, err := GvmDir()
+func CurrentVersion(ctx context.Context) (string, error) {
+    d, err := GvmDir(ctx)
     if err != nil {
         return "", err
     }
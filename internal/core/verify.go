@@ -0,0 +1,285 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// Manifest lists the files of a pristine installation, keyed by slash-separated
+// path relative to the version directory
+type Manifest struct {
+	// Files maps regular files to their SHA-256
+	Files map[string]string `json:"files"`
+	// Symlinks maps symlinks to their targets
+	Symlinks map[string]string `json:"symlinks,omitempty"`
+}
+
+// VerifyResult reports how an installed version differs from its manifest
+type VerifyResult struct {
+	Version  string
+	Files    int
+	Modified []string
+	Missing  []string
+	Extra    []string
+	// NoManifest is set for linked versions and versions installed before
+	// manifests were recorded; they cannot be verified
+	NoManifest bool
+}
+
+// OK reports whether the installation matches its manifest
+func (r *VerifyResult) OK() bool {
+	return !r.NoManifest && len(r.Modified)+len(r.Missing)+len(r.Extra) == 0
+}
+
+func manifestPath(ctx context.Context, version string) (string, error) {
+	dir, err := receiptsDir(ctx)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "go"+version+".manifest.json"), nil
+}
+
+// buildManifest hashes every file below root
+func buildManifest(root string) (*Manifest, error) {
+	m := &Manifest{Files: map[string]string{}, Symlinks: map[string]string{}}
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, p)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		switch {
+		case d.Type()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(p)
+			if err != nil {
+				return err
+			}
+			m.Symlinks[rel] = filepath.ToSlash(target)
+		case d.Type().IsRegular():
+			sum, err := fileSHA256(p)
+			if err != nil {
+				return err
+			}
+			m.Files[rel] = sum
+		}
+		return nil
+	})
+	return m, err
+}
+
+// recordManifest stores the manifest of a freshly installed version
+func recordManifest(ctx context.Context, version, vdir string) error {
+	m, err := buildManifest(vdir)
+	if err != nil {
+		return err
+	}
+	p, err := manifestPath(ctx, version)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	return writeJSONFile(p, m)
+}
+
+func loadManifest(ctx context.Context, version string) (*Manifest, error) {
+	p, err := manifestPath(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(p)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", p, err)
+	}
+	return &m, nil
+}
+
+func removeManifest(ctx context.Context, version string) error {
+	p, err := manifestPath(ctx, version)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// VerifyVersion compares the installed version matching spec with the
+// manifest recorded when it was installed
+func VerifyVersion(ctx context.Context, spec string) (*VerifyResult, error) {
+	version, _, err := resolveInstalledVersion(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	return verifyVersion(ctx, version)
+}
+
+// VerifyAll verifies every installed version
+func VerifyAll(ctx context.Context) ([]*VerifyResult, error) {
+	versions, err := ListLocal(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []*VerifyResult
+	for _, v := range versions {
+		r, err := verifyVersion(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+func verifyVersion(ctx context.Context, version string) (*VerifyResult, error) {
+	if err := interrupted(ctx); err != nil {
+		return nil, err
+	}
+	d, err := GvmDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r := &VerifyResult{Version: version}
+	want, err := loadManifest(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+	if want == nil {
+		r.NoManifest = true
+		return r, nil
+	}
+	have, err := buildManifest(filepath.Join(d, "go"+version))
+	if err != nil {
+		return nil, err
+	}
+	w, h := want.entries(), have.entries()
+	r.Files = len(w)
+	for p, e := range w {
+		got, ok := h[p]
+		switch {
+		case !ok:
+			r.Missing = append(r.Missing, p)
+		case got != e:
+			r.Modified = append(r.Modified, p)
+		}
+	}
+	for p := range h {
+		if _, ok := w[p]; !ok {
+			r.Extra = append(r.Extra, p)
+		}
+	}
+	sort.Strings(r.Modified)
+	sort.Strings(r.Missing)
+	sort.Strings(r.Extra)
+	return r, nil
+}
+
+// entries merges files and symlinks, so a file replaced by a symlink counts as modified
+func (m *Manifest) entries() map[string]string {
+	out := make(map[string]string, len(m.Files)+len(m.Symlinks))
+	for p, sum := range m.Files {
+		out[p] = "sha256:" + sum
+	}
+	for p, target := range m.Symlinks {
+		out[p] = "symlink:" + target
+	}
+	return out
+}
+
+// RepairVersion restores a pristine copy of an installed version by
+// re-extracting its archive from the download cache, re-downloading it if needed
+func RepairVersion(ctx context.Context, version string) error {
+	return withLock(ctx, func(ctx context.Context) error { return repairVersion(ctx, version) })
+}
+
+func repairVersion(ctx context.Context, version string) error {
+	d, err := GvmDir(ctx)
+	if err != nil {
+		return err
+	}
+	receipt, err := LoadReceipt(ctx, version)
+	if err != nil {
+		return err
+	}
+	if receipt == nil {
+		return fmt.Errorf("no install receipt for go%s, reinstall it to repair", version)
+	}
+	if receipt.Linked {
+		return fmt.Errorf("go%s is linked to %s and is not managed by gvm", version, receipt.Source)
+	}
+	archive, err := receiptArchive(ctx, receipt)
+	if err != nil {
+		return err
+	}
+
+	cleanLeftoverStaging(ctx)
+	staging, err := StagingDir(ctx)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(staging, 0o755); err != nil {
+		return err
+	}
+	// If gvm dies before the new tree is in place, CleanStaging moves the old one back
+	old, err := os.MkdirTemp(staging, "go"+version+replacedSuffix+"*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(old)
+
+	vdir := filepath.Join(d, "go"+version)
+	logf(ctx, "📦 Extracting...\n")
+	if err := os.Rename(vdir, filepath.Join(old, "go")); err != nil {
+		return err
+	}
+	if err := stageArchive(ctx, archive, vdir); err != nil {
+		if rerr := os.Rename(filepath.Join(old, "go"), vdir); rerr != nil {
+			return fmt.Errorf("%v (restoring the previous tree also failed: %v)", err, rerr)
+		}
+		return err
+	}
+	return recordManifest(ctx, version, vdir)
+}
+
+// receiptArchive returns a verified copy of the archive a version was installed from
+func receiptArchive(ctx context.Context, r *Receipt) (string, error) {
+	archives, err := ListCachedArchives(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, a := range archives {
+		if a.SHA256 == r.SHA256 && verifyChecksum(a.Path, r.SHA256) == nil {
+			return a.Path, nil
+		}
+	}
+	if u, err := url.Parse(r.Source); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		return fetchArchive(ctx, r.Source, path.Base(u.Path), r.SHA256)
+	}
+	if _, err := os.Stat(r.Source); err == nil {
+		if err := verifyChecksum(r.Source, r.SHA256); err != nil {
+			return "", fmt.Errorf("%s has changed since go%s was installed: %v", r.Source, r.Version, err)
+		}
+		return r.Source, nil
+	}
+	return "", fmt.Errorf("archive for go%s (sha256 %s) is not cached and %s is not available", r.Version, r.SHA256, r.Source)
+}
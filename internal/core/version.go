@@ -0,0 +1,138 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Version is a parsed Go release version such as "1.22.5", "1.21" or "1.22rc1"
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+	// Pre is "beta" or "rc" for prereleases, empty for releases
+	Pre string
+	// PreNum is the prerelease number, e.g. 2 for "1.24rc2"
+	PreNum int
+
+	raw string
+}
+
+// versionRe matches major[.minor[.patch]] or major.minor{beta,rc}N; a
+// prerelease suffix never follows a patch number
+var versionRe = regexp.MustCompile(`^(\d+)(?:\.(\d+)(?:\.(\d+)|(beta|rc)(\d+))?)?$`)
+
+// ParseVersion parses a Go version with or without the "go" prefix.
+// Missing minor and patch numbers are zero, so "go1.21" equals "go1.21.0".
+func ParseVersion(s string) (Version, error) {
+	raw := strings.TrimPrefix(strings.TrimSpace(s), "go")
+	m := versionRe.FindStringSubmatch(raw)
+	if m == nil {
+		return Version{}, errorf(ErrInvalidVersion, "invalid version: %s", s)
+	}
+	v := Version{Pre: m[4], raw: raw}
+	v.Major, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		v.Minor, _ = strconv.Atoi(m[2])
+	}
+	if m[3] != "" {
+		v.Patch, _ = strconv.Atoi(m[3])
+	}
+	if m[5] != "" {
+		v.PreNum, _ = strconv.Atoi(m[5])
+	}
+	return v, nil
+}
+
+// String returns the version as it was written, without the "go" prefix
+func (v Version) String() string {
+	if v.raw != "" {
+		return v.raw
+	}
+	s := fmt.Sprintf("%d.%d", v.Major, v.Minor)
+	if v.Pre != "" {
+		return fmt.Sprintf("%s%s%d", s, v.Pre, v.PreNum)
+	}
+	return fmt.Sprintf("%s.%d", s, v.Patch)
+}
+
+// MinorString returns the minor version line, e.g. "1.22" for "1.22.5"
+func (v Version) MinorString() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// IsPrerelease reports whether v is a beta or release candidate
+func (v Version) IsPrerelease() bool {
+	return v.Pre != ""
+}
+
+// SameMinor reports whether v and o belong to the same minor version line
+func (v Version) SameMinor(o Version) bool {
+	return v.Major == o.Major && v.Minor == o.Minor
+}
+
+// Compare orders versions the way Go does: by major, minor and patch, with
+// betas before release candidates before the release itself.
+// Returns 1 if v > o, -1 if v < o, 0 if equal.
+func (v Version) Compare(o Version) int {
+	for _, d := range []int{v.Major - o.Major, v.Minor - o.Minor, v.Patch - o.Patch, preRank(v.Pre) - preRank(o.Pre), v.PreNum - o.PreNum} {
+		if d > 0 {
+			return 1
+		}
+		if d < 0 {
+			return -1
+		}
+	}
+	return 0
+}
+
+func preRank(pre string) int {
+	switch pre {
+	case "beta":
+		return 0
+	case "rc":
+		return 1
+	}
+	return 2
+}
+
+// matchVersionPrefix reports whether v starts with the numeric components of
+// prefix, e.g. "1.22" matches 1.22, 1.22.5 and 1.22rc1 but not 1.2.x.
+// A prerelease prefix must match exactly.
+func matchVersionPrefix(v Version, prefix string) bool {
+	prefix = strings.TrimPrefix(prefix, "go")
+	p, err := ParseVersion(prefix)
+	if err != nil {
+		return false
+	}
+	if p.IsPrerelease() {
+		return v.Compare(p) == 0
+	}
+	n := strings.Count(prefix, ".")
+	if v.Major != p.Major || (n >= 1 && v.Minor != p.Minor) {
+		return false
+	}
+	return n < 2 || (v.Patch == p.Patch && !v.IsPrerelease())
+}
+
+// sortVersions sorts version strings in ascending order. Unparseable
+// versions sort first, in lexical order.
+func sortVersions(vv []string) {
+	sort.SliceStable(vv, func(i, j int) bool {
+		return compareVersions(vv[i], vv[j]) < 0
+	})
+}
+
+// latestVersion returns the highest version in vv
+func latestVersion(vv []string) string {
+	best := ""
+	for _, v := range vv {
+		if best == "" || compareVersions(v, best) > 0 {
+			best = v
+		}
+	}
+	return best
+}
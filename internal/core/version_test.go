@@ -0,0 +1,108 @@
+package core
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Version
+	}{
+		{"1.22.5", Version{Major: 1, Minor: 22, Patch: 5}},
+		{"go1.22.5", Version{Major: 1, Minor: 22, Patch: 5}},
+		{"go1.21", Version{Major: 1, Minor: 21}},
+		{"1", Version{Major: 1}},
+		{"1.24rc2", Version{Major: 1, Minor: 24, Pre: "rc", PreNum: 2}},
+		{"go1.23beta1", Version{Major: 1, Minor: 23, Pre: "beta", PreNum: 1}},
+		{" 1.25.10 ", Version{Major: 1, Minor: 25, Patch: 10}},
+	}
+	for _, tt := range tests {
+		got, err := ParseVersion(tt.in)
+		if err != nil {
+			t.Errorf("ParseVersion(%q): %v", tt.in, err)
+			continue
+		}
+		got.raw = ""
+		if got != tt.want {
+			t.Errorf("ParseVersion(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseVersionInvalid(t *testing.T) {
+	for _, in := range []string{"", "go", "latest", "1.", "1.22.", "1.22.5rc1", "1.22.5beta2", "1rc1", "1.22rc", "1.22alpha1", "v1.22", "1.22.5.1", "1.x"} {
+		if v, err := ParseVersion(in); !errors.Is(err, ErrInvalidVersion) {
+			t.Errorf("ParseVersion(%q) = %+v, %v; want ErrInvalidVersion", in, v, err)
+		}
+	}
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.25.10", "1.25.9", 1},
+		{"1.10", "1.9", 1},
+		{"1.22.0", "1.21.13", 1},
+		{"2.0", "1.99.99", 1},
+		{"1.24rc1", "1.24.0", -1},
+		{"1.24rc2", "1.24", -1},
+		{"1.24beta1", "1.24rc1", -1},
+		{"1.24rc2", "1.24rc10", -1},
+		{"1.24rc1", "1.23.9", 1},
+		{"go1.21", "go1.21.0", 0},
+		{"1.21", "1.21.0", 0},
+		{"go1.22.5", "1.22.5", 0},
+		{"bogus", "1.0", -1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+		if got := compareVersions(tt.b, tt.a); got != -tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.b, tt.a, got, -tt.want)
+		}
+	}
+}
+
+func TestSortVersions(t *testing.T) {
+	vv := []string{"1.25.10", "1.9", "1.25rc1", "1.25.9", "1.25", "1.10.1", "1.25beta1", "bogus"}
+	sortVersions(vv)
+	want := []string{"bogus", "1.9", "1.10.1", "1.25beta1", "1.25rc1", "1.25", "1.25.9", "1.25.10"}
+	if !slices.Equal(vv, want) {
+		t.Errorf("sortVersions = %v, want %v", vv, want)
+	}
+	if got := latestVersion(vv); got != "1.25.10" {
+		t.Errorf("latestVersion = %q, want 1.25.10", got)
+	}
+}
+
+func TestMatchVersionPrefix(t *testing.T) {
+	tests := []struct {
+		v, prefix string
+		want      bool
+	}{
+		{"1.22.5", "1.22", true},
+		{"1.22rc1", "1.22", true},
+		{"1.2.3", "1.22", false},
+		{"1.22.5", "1", true},
+		{"1.22.5", "1.22.5", true},
+		{"1.22.50", "1.22.5", false},
+		{"1.22rc1", "1.22rc1", true},
+		{"1.22rc2", "1.22rc1", false},
+		{"1.22.5", "go1.22", true},
+	}
+	for _, tt := range tests {
+		v, err := ParseVersion(tt.v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := matchVersionPrefix(v, tt.prefix); got != tt.want {
+			t.Errorf("matchVersionPrefix(%s, %q) = %v, want %v", tt.v, tt.prefix, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package core
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func writeZip(t *testing.T, entries []entry) string {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		if e.link {
+			t.Fatal("zip archives have no hardlinks")
+		}
+		fh := &zip.FileHeader{Name: e.name, Method: zip.Deflate, Modified: e.mtime}
+		fh.SetMode(e.mode)
+		w, err := zw.CreateHeader(fh)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !e.mode.IsDir() {
+			if _, err := w.Write([]byte(e.body)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	p := filepath.Join(t.TempDir(), "go.zip")
+	if err := os.WriteFile(p, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestArchiveFormat(t *testing.T) {
+	tests := []struct {
+		name, want string
+	}{
+		{"go1.22.5.windows-amd64.zip", formatZip},
+		{"/mirror/GO1.22.5.LINUX-AMD64.ZIP", formatZip},
+		{"go1.22.5.linux-amd64.tar.gz", formatTarGz},
+		{"go.tgz", formatTarGz},
+		{"go1.22.5.darwin-arm64.pkg", ""},
+		{"go1.22.5.linux-amd64.tar.xz", ""},
+	}
+	for _, tt := range tests {
+		got, err := archiveFormat(tt.name)
+		if got != tt.want || (err != nil) != (tt.want == "") {
+			t.Errorf("archiveFormat(%q) = %q, %v; want %q", tt.name, got, err, tt.want)
+		}
+	}
+	if ext := archiveExt("windows"); ext != ".zip" {
+		t.Errorf("archiveExt(windows) = %q, want .zip", ext)
+	}
+	if ext := archiveExt("linux"); ext != ".tar.gz" {
+		t.Errorf("archiveExt(linux) = %q, want .tar.gz", ext)
+	}
+}
+
+// goZip is a minimal Go distribution as a zip, without directory entries like
+// many repackaged mirrors
+func goZip(t *testing.T) string {
+	return writeZip(t, []entry{
+		fileEntry("go/VERSION", "go1.22.5\ntime 2024-06-04T20:50:19Z\n"),
+		{name: "go/bin/go", body: "binary", mode: 0o755},
+		{name: "go/bin/gofmt", body: "binary", mode: 0o755},
+		fileEntry("go/src/fmt/print.go", "package fmt"),
+	})
+}
+
+func TestExtractZip(t *testing.T) {
+	archive := goZip(t)
+	if v, err := archiveVersion(archive); err != nil || v != "1.22.5" {
+		t.Fatalf("archiveVersion = %q, %v; want 1.22.5", v, err)
+	}
+
+	dest := t.TempDir()
+	if err := extractArchive(context.Background(), archive, dest); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dest, "go/src/fmt/print.go"))
+	if err != nil || string(b) != "package fmt" {
+		t.Fatalf("go/src/fmt/print.go = %q, %v", b, err)
+	}
+	fi, err := os.Stat(filepath.Join(dest, "go/bin/go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if runtime.GOOS != "windows" && fi.Mode().Perm()&0o100 == 0 {
+		t.Errorf("go/bin/go mode = %v, want executable", fi.Mode())
+	}
+}
+
+func TestExtractZipCorrupt(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "go.zip")
+	if err := os.WriteFile(p, []byte("not a zip"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := extractArchive(context.Background(), p, t.TempDir()); err == nil {
+		t.Fatal("extracting a corrupt zip succeeded")
+	}
+}
+
+func TestInstallFromZipFile(t *testing.T) {
+	ctx, root := fakeRoot(t)
+	v, err := InstallFromFile(ctx, goZip(t), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v != "1.22.5" {
+		t.Fatalf("installed %q, want 1.22.5", v)
+	}
+	if _, err := os.Stat(filepath.Join(root, "go1.22.5", "bin", "gofmt")); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,29 @@
+package gvm
+
+import (
+	"context"
+
+	"github.com/ibreez3/gvm/internal/core"
+)
+
+// Aliases returns the alias name to version mapping
+func (m *Manager) Aliases(ctx context.Context) (map[string]string, error) {
+	return core.LoadAliases(m.context(ctx))
+}
+
+// SetAlias points name at the installed version matching spec and returns
+// that version. Aliases can be used wherever an installed version is expected.
+func (m *Manager) SetAlias(ctx context.Context, name, spec string) (string, error) {
+	return core.SetAlias(m.context(ctx), name, spec)
+}
+
+// RemoveAlias deletes an alias
+func (m *Manager) RemoveAlias(ctx context.Context, name string) error {
+	return core.RemoveAlias(m.context(ctx), name)
+}
+
+// StaleAliases returns the sorted alias names pointing at an older patch of
+// the same minor version as version, i.e. the aliases an upgrade could move
+func (m *Manager) StaleAliases(ctx context.Context, version string) ([]string, error) {
+	return core.StaleAliases(m.context(ctx), version)
+}
@@ -0,0 +1,34 @@
+package gvm
+
+import (
+	"context"
+
+	"github.com/ibreez3/gvm/internal/core"
+)
+
+// DefaultConfig returns a config with the default download source
+func DefaultConfig() *Config {
+	return core.DefaultConfig()
+}
+
+// Config returns the configuration in config.json, or the defaults if the
+// file does not exist
+func (m *Manager) Config(ctx context.Context) (*Config, error) {
+	return core.LoadConfig(m.context(ctx))
+}
+
+// SaveConfig writes cfg to config.json
+func (m *Manager) SaveConfig(ctx context.Context, cfg *Config) error {
+	return core.SaveConfig(m.context(ctx), cfg)
+}
+
+// CheckUpdate reports whether a newer gvm release than the running one exists
+// and returns its tag
+func (m *Manager) CheckUpdate(ctx context.Context) (bool, string, error) {
+	return core.CheckUpdate(m.context(ctx))
+}
+
+// SelfUpdate replaces the running gvm executable with the latest release
+func (m *Manager) SelfUpdate(ctx context.Context) error {
+	return core.SelfUpdate(m.context(ctx))
+}
@@ -0,0 +1,49 @@
+package gvm
+
+import (
+	"context"
+
+	"github.com/ibreez3/gvm/internal/core"
+)
+
+// Init creates the gvm directory, its .gvmrc and the shims, and sources the
+// .gvmrc from the user's shell profile
+func (m *Manager) Init(ctx context.Context) error {
+	return core.InitEnv(m.context(ctx))
+}
+
+// Link registers an existing Go installation at path as an installed version
+// without copying it
+func (m *Manager) Link(ctx context.Context, path string) error {
+	return core.LinkVersion(m.context(ctx), path)
+}
+
+// SessionEnv returns shell statements that select the installed version
+// matching spec for the current shell only
+func (m *Manager) SessionEnv(ctx context.Context, spec string) ([]string, error) {
+	return core.SessionEnv(m.context(ctx), spec)
+}
+
+// UnsetSessionEnv returns shell statements that drop the session version and
+// fall back to the project version for dir or the global default
+func (m *Manager) UnsetSessionEnv(ctx context.Context, dir string) ([]string, error) {
+	return core.UnsetSessionEnv(m.context(ctx), dir)
+}
+
+// ProjectEnv returns shell statements that select the project version for dir
+// in the current shell only. If the project version is not installed, the
+// statements fall back to the global default and are returned with the error.
+func (m *Manager) ProjectEnv(ctx context.Context, dir string) ([]string, error) {
+	return core.ProjectEnv(m.context(ctx), dir)
+}
+
+// Rehash (re)creates the go and gofmt shims, pointing them at the running
+// executable
+func (m *Manager) Rehash(ctx context.Context) error {
+	return core.Rehash(m.context(ctx))
+}
+
+// ShimsDir returns the directory holding the shims
+func (m *Manager) ShimsDir(ctx context.Context) (string, error) {
+	return core.ShimsDir(m.context(ctx))
+}
@@ -0,0 +1,18 @@
+package gvm
+
+import (
+	"net/http"
+
+	"github.com/ibreez3/gvm/internal/core"
+)
+
+// HTTPConfig configures proxy, extra CA certificates, mTLS client
+// certificate, timeout and retries, like the http section of config.json
+type HTTPConfig = core.HTTPConfig
+
+// NewHTTPClient returns a client for Manager.HTTPClient built from cfg.
+// Requests failing with a 5xx or 429 status or a reset connection are retried
+// with exponential backoff.
+func NewHTTPClient(cfg *HTTPConfig) (*http.Client, error) {
+	return core.NewHTTPClient(cfg)
+}
@@ -0,0 +1,62 @@
+package gvm
+
+import (
+	"context"
+	"time"
+
+	"github.com/ibreez3/gvm/internal/core"
+)
+
+// Verify compares the installed version matching spec with the file manifest
+// recorded when it was installed
+func (m *Manager) Verify(ctx context.Context, spec string) (*VerifyResult, error) {
+	return core.VerifyVersion(m.context(ctx), spec)
+}
+
+// VerifyAll verifies every installed version
+func (m *Manager) VerifyAll(ctx context.Context) ([]*VerifyResult, error) {
+	return core.VerifyAll(m.context(ctx))
+}
+
+// Repair restores an installed version from its archive in the download
+// cache, downloading it again if needed
+func (m *Manager) Repair(ctx context.Context, version string) error {
+	return core.RepairVersion(m.context(ctx), version)
+}
+
+// Doctor diagnoses common environment problems. Checks that CanFix are
+// repaired in m's gvm directory by their Fix method.
+func (m *Manager) Doctor(ctx context.Context) ([]*Check, error) {
+	return core.Doctor(m.context(ctx))
+}
+
+// CacheDir returns the download cache directory
+func (m *Manager) CacheDir(ctx context.Context) (string, error) {
+	return core.CacheDir(m.context(ctx))
+}
+
+// CachedArchives returns the archives in the download cache, oldest first
+func (m *Manager) CachedArchives(ctx context.Context) ([]CachedArchive, error) {
+	return core.ListCachedArchives(m.context(ctx))
+}
+
+// CacheSize returns the size in bytes of the download cache
+func (m *Manager) CacheSize(ctx context.Context) (int64, error) {
+	return core.CacheSize(m.context(ctx))
+}
+
+// CleanCache removes cached archives not used within olderThan and returns
+// the number of bytes freed. A zero olderThan empties the cache.
+func (m *Manager) CleanCache(ctx context.Context, olderThan time.Duration) (int64, error) {
+	return core.CleanCache(m.context(ctx), olderThan)
+}
+
+// FormatSize formats a byte count in KB or MB
+func FormatSize(b int64) string {
+	return core.FormatSize(b)
+}
+
+// ParseAge parses a duration that also accepts a day suffix, e.g. "30d"
+func ParseAge(s string) (time.Duration, error) {
+	return core.ParseAge(s)
+}
@@ -0,0 +1,195 @@
+// Package gvm manages Go toolchains from Go programs. It exposes the
+// operations behind the gvm command (install, use, list, search, uninstall,
+// upgrade, project resolution, aliases, verification and cache maintenance)
+// as methods that return values instead of printing them.
+//
+//	m := &gvm.Manager{Root: "/opt/gvm"}
+//	version, err := m.Install(ctx, "1.22")
+//	if err != nil && !errors.Is(err, gvm.ErrAlreadyInstalled) {
+//		return err
+//	}
+//	_, err = m.Use(ctx, version)
+package gvm
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ibreez3/gvm/internal/core"
+)
+
+// Types returned by Manager methods
+type (
+	InstalledVersion   = core.InstalledVersion
+	Receipt            = core.Receipt
+	UpgradeResult      = core.UpgradeResult
+	Resolution         = core.Resolution
+	ProjectVersion     = core.ProjectVersion
+	UninstallBatchSpec = core.UninstallBatchSpec
+	UninstallFailure   = core.UninstallFailure
+	Active             = core.Active
+	VersionSource      = core.VersionSource
+	CachedArchive      = core.CachedArchive
+	VerifyResult       = core.VerifyResult
+	Check              = core.Check
+	CheckStatus        = core.CheckStatus
+	Config             = core.Config
+)
+
+// Sources of the version returned by Manager.Current
+const (
+	SourceSession = core.SourceSession
+	SourceProject = core.SourceProject
+	SourceGlobal  = core.SourceGlobal
+)
+
+// SessionEnvVar is the environment variable holding the session version
+// selected by SessionEnv
+const SessionEnvVar = core.SessionEnvVar
+
+// Doctor check statuses, from healthy to broken
+const (
+	CheckOK    = core.CheckOK
+	CheckWarn  = core.CheckWarn
+	CheckError = core.CheckError
+)
+
+// Errors returned by Manager methods, for use with errors.Is
+var (
+	ErrInvalidVersion   = core.ErrInvalidVersion
+	ErrVersionNotFound  = core.ErrVersionNotFound
+	ErrNotInstalled     = core.ErrNotInstalled
+	ErrAlreadyInstalled = core.ErrAlreadyInstalled
+	ErrChecksumMismatch = core.ErrChecksumMismatch
+	ErrUnsafeArchive    = core.ErrUnsafeArchive
+	ErrNetwork          = core.ErrNetwork
+	ErrLocked           = core.ErrLocked
+	ErrInterrupted      = core.ErrInterrupted
+)
+
+// BatchUninstallError is returned by UninstallBatch when some versions could
+// not be removed; use errors.As to list them
+type BatchUninstallError = core.BatchUninstallError
+
+// Manager installs and selects Go versions below a gvm directory. The zero
+// value manages ~/.gvm silently.
+//
+// Managers are safe for concurrent use, also with different settings. Changes
+// to a gvm directory are serialized, within a process and with other
+// processes, by its lock file. Canceling the context of a call stops
+// downloads, extraction and waiting for the lock; partially extracted versions
+// are removed and the error wraps ErrInterrupted.
+type Manager struct {
+	// Root is the gvm directory; empty means ~/.gvm
+	Root string
+	// HTTPClient is used for the version index and downloads; nil means a
+	// client built from the http section of Root's config.json, see
+	// NewHTTPClient
+	HTTPClient *http.Client
+	// Logger receives status messages; nil discards them
+	Logger Logger
+	// Progress receives download progress; nil disables it
+	Progress Progress
+	// Offline only uses the cached version index and download cache
+	Offline bool
+	// LockTimeout is how long a change waits for another process holding the
+	// lock; zero means GVM_LOCK_TIMEOUT or 5 minutes
+	LockTimeout time.Duration
+}
+
+// context returns ctx carrying m's settings for the core package
+func (m *Manager) context(ctx context.Context) context.Context {
+	return core.WithSettings(ctx, core.Settings{
+		Root:        m.Root,
+		HTTPClient:  m.HTTPClient,
+		Log:         m.Logger,
+		Progress:    m.Progress,
+		Offline:     m.Offline,
+		LockTimeout: m.LockTimeout,
+	})
+}
+
+// Install installs the version matching spec, which may be an exact version,
+// a keyword (latest, stable, oldstable), a minor version or a constraint
+// resolved against the remote index. It returns the exact version; if that
+// version is already installed the error wraps ErrAlreadyInstalled.
+func (m *Manager) Install(ctx context.Context, spec string) (string, error) {
+	return core.InstallVersion(m.context(ctx), spec)
+}
+
+// InstallFile installs a Go archive from a local file. The version is read
+// from the archive; if sum is set, the file's SHA-256 must match it.
+func (m *Manager) InstallFile(ctx context.Context, file, sum string) (string, error) {
+	return core.InstallFromFile(m.context(ctx), file, sum)
+}
+
+// InstallURL downloads a Go archive from url and installs it. If sum is set,
+// the download is verified against it.
+func (m *Manager) InstallURL(ctx context.Context, url, sum string) (string, error) {
+	return core.InstallFromURL(m.context(ctx), url, sum)
+}
+
+// Use makes the installed version matching spec the global default and
+// returns the exact version
+func (m *Manager) Use(ctx context.Context, spec string) (string, error) {
+	return core.UseVersion(m.context(ctx), spec)
+}
+
+// Current returns the version that applies in dir and where it was selected:
+// the session version from GVM_VERSION ("gvm shell"), then the project
+// version, then the global default
+func (m *Manager) Current(ctx context.Context, dir string) (*Active, error) {
+	return core.ActiveVersion(m.context(ctx), dir)
+}
+
+// Default returns the global default version selected by Use
+func (m *Manager) Default(ctx context.Context) (string, error) {
+	return core.CurrentVersion(m.context(ctx))
+}
+
+// List returns the installed versions, oldest first
+func (m *Manager) List(ctx context.Context) ([]*InstalledVersion, error) {
+	return core.ListInstalled(m.context(ctx))
+}
+
+// Info describes the installed version matching spec
+func (m *Manager) Info(ctx context.Context, spec string) (*InstalledVersion, error) {
+	return core.VersionInfo(m.context(ctx), spec)
+}
+
+// ListRemote returns the n newest released versions, newest first. Betas and
+// release candidates are only included if unstable is set.
+func (m *Manager) ListRemote(ctx context.Context, n int, unstable bool) ([]string, error) {
+	return core.ListRemote(m.context(ctx), n, unstable)
+}
+
+// Search returns up to limit released versions matching prefix (e.g. "1.22"),
+// newest first. A limit of 0 returns all matches.
+func (m *Manager) Search(ctx context.Context, prefix string, limit int, prerelease bool) ([]string, error) {
+	return core.SearchRemote(m.context(ctx), prefix, limit, prerelease)
+}
+
+// Uninstall removes an installed version. Versions that an alias points at
+// are only removed with force, which also deletes those aliases.
+func (m *Manager) Uninstall(ctx context.Context, version string, force bool) error {
+	return core.UninstallVersion(m.context(ctx), version, force)
+}
+
+// UninstallBatch removes the versions selected by spec and returns them. If
+// some versions fail, the others are still removed and returned with a
+// *BatchUninstallError.
+func (m *Manager) UninstallBatch(ctx context.Context, spec *UninstallBatchSpec) ([]string, error) {
+	return core.UninstallBatch(m.context(ctx), spec)
+}
+
+// Upgrade installs the newest patch of a minor version such as "1.22"
+func (m *Manager) Upgrade(ctx context.Context, minor string) (*UpgradeResult, error) {
+	return core.UpgradeVersion(m.context(ctx), minor)
+}
+
+// Resolve returns the installed version selected by the project files
+// (.go-version, go.work, go.mod) in dir or its parents
+func (m *Manager) Resolve(ctx context.Context, dir string) (*Resolution, error) {
+	return core.ResolveProjectVersion(m.context(ctx), dir)
+}
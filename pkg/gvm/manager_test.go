@@ -0,0 +1,282 @@
+package gvm
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// goArchive writes a minimal Go release zip for version and returns its path
+func goArchive(t *testing.T, version string) string {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "go"+version+".zip")
+	f, err := os.Create(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zw := zip.NewWriter(f)
+	for name, body := range map[string]string{
+		"go/VERSION":   "go" + version + "\n",
+		"go/bin/go":    "binary",
+		"go/bin/gofmt": "binary",
+	} {
+		fh := &zip.FileHeader{Name: name, Method: zip.Deflate}
+		fh.SetMode(0o755)
+		w, err := zw.CreateHeader(fh)
+		if err == nil {
+			_, err = w.Write([]byte(body))
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+// testEnv isolates the shell profile and session version from the user's
+func testEnv(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(SessionEnvVar, "")
+}
+
+func installAll(t *testing.T, m *Manager, versions ...string) {
+	t.Helper()
+	for _, v := range versions {
+		got, err := m.InstallFile(context.Background(), goArchive(t, v), "")
+		if err != nil {
+			t.Fatalf("InstallFile(%s): %v", v, err)
+		}
+		if got != v {
+			t.Fatalf("InstallFile installed %s, want %s", got, v)
+		}
+	}
+}
+
+func TestManager(t *testing.T) {
+	testEnv(t)
+	ctx := context.Background()
+	m := &Manager{Root: t.TempDir()}
+	installAll(t, m, "1.21.13", "1.22.5")
+
+	if _, err := m.InstallFile(ctx, goArchive(t, "1.22.5"), ""); !errors.Is(err, ErrAlreadyInstalled) {
+		t.Fatalf("reinstall: %v, want ErrAlreadyInstalled", err)
+	}
+	list, err := m.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var versions []string
+	for _, iv := range list {
+		versions = append(versions, iv.Version)
+	}
+	if !slices.Equal(versions, []string{"1.21.13", "1.22.5"}) {
+		t.Fatalf("List = %v", versions)
+	}
+
+	if v, err := m.Use(ctx, "1.22"); err != nil || v != "1.22.5" {
+		t.Fatalf("Use(1.22) = %q, %v", v, err)
+	}
+	if v, err := m.Default(ctx); err != nil || v != "1.22.5" {
+		t.Fatalf("Default = %q, %v", v, err)
+	}
+	info, err := m.Info(ctx, "1.21")
+	if err != nil || info.Version != "1.21.13" {
+		t.Fatalf("Info(1.21) = %+v, %v", info, err)
+	}
+
+	if v, err := m.SetAlias(ctx, "legacy", "1.21"); err != nil || v != "1.21.13" {
+		t.Fatalf("SetAlias = %q, %v", v, err)
+	}
+	if aliases, err := m.Aliases(ctx); err != nil || aliases["legacy"] != "1.21.13" {
+		t.Fatalf("Aliases = %v, %v", aliases, err)
+	}
+	if err := m.Uninstall(ctx, "1.21.13", false); err == nil {
+		t.Fatal("uninstalled a version an alias points at without force")
+	}
+	if err := m.Uninstall(ctx, "1.21.13", true); err != nil {
+		t.Fatal(err)
+	}
+	if aliases, err := m.Aliases(ctx); err != nil || len(aliases) != 0 {
+		t.Fatalf("Aliases after forced uninstall = %v, %v", aliases, err)
+	}
+	if _, err := m.Info(ctx, "1.21.13"); !errors.Is(err, ErrNotInstalled) {
+		t.Fatalf("Info after uninstall: %v, want ErrNotInstalled", err)
+	}
+}
+
+func TestManagerCurrent(t *testing.T) {
+	testEnv(t)
+	ctx := context.Background()
+	m := &Manager{Root: t.TempDir()}
+	installAll(t, m, "1.21.13", "1.22.5")
+	if _, err := m.Use(ctx, "1.22.5"); err != nil {
+		t.Fatal(err)
+	}
+
+	project := t.TempDir()
+	if err := os.WriteFile(filepath.Join(project, "go.mod"), []byte("module p\n\ngo 1.21\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		dir, session string
+		want         string
+		source       VersionSource
+	}{
+		{dir: t.TempDir(), want: "1.22.5", source: SourceGlobal},
+		{dir: project, want: "1.21.13", source: SourceProject},
+		{dir: project, session: "go1.22.5", want: "1.22.5", source: SourceSession},
+	}
+	for _, tt := range tests {
+		t.Setenv(SessionEnvVar, tt.session)
+		a, err := m.Current(ctx, tt.dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a.Version != tt.want || a.Source != tt.source {
+			t.Errorf("Current(%s) with %s=%q = %s (%s), want %s (%s)", tt.dir, SessionEnvVar, tt.session, a.Version, a.Source, tt.want, tt.source)
+		}
+		if tt.source == SourceProject && (a.Project == nil || a.Project.File != filepath.Join(project, "go.mod")) {
+			t.Errorf("Current(%s).Project = %+v, want go.mod", tt.dir, a.Project)
+		}
+	}
+}
+
+func TestManagersRunConcurrently(t *testing.T) {
+	testEnv(t)
+	versions := []string{"1.20.14", "1.21.13", "1.22.5", "1.23.1"}
+	managers := make([]*Manager, len(versions))
+	archives := make([]string, len(versions))
+	for i, v := range versions {
+		managers[i] = &Manager{Root: t.TempDir()}
+		archives[i] = goArchive(t, v)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(versions))
+	for i := range versions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m := managers[i]
+			if _, err := m.InstallFile(context.Background(), archives[i], ""); err != nil {
+				errs[i] = err
+				return
+			}
+			_, errs[i] = m.Use(context.Background(), versions[i])
+		}()
+	}
+	wg.Wait()
+
+	for i, m := range managers {
+		if errs[i] != nil {
+			t.Fatalf("%s: %v", versions[i], errs[i])
+		}
+		list, err := m.List(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(list) != 1 || list[0].Version != versions[i] {
+			t.Errorf("%s lists %d versions, want only %s", m.Root, len(list), versions[i])
+		}
+		if v, err := m.Default(context.Background()); err != nil || v != versions[i] {
+			t.Errorf("%s: Default = %q, %v; want %s", m.Root, v, err, versions[i])
+		}
+	}
+}
+
+// reentrantLogger queries its Manager while an operation is running
+type reentrantLogger struct {
+	m     *Manager
+	calls int
+	err   error
+}
+
+func (l *reentrantLogger) Infof(format string, args ...any) {
+	l.calls++
+	if _, err := l.m.List(context.Background()); err != nil {
+		l.err = fmt.Errorf("List from Infof: %w", err)
+	}
+}
+
+func (l *reentrantLogger) Warnf(format string, args ...any) {}
+
+func TestLoggerCanCallManager(t *testing.T) {
+	testEnv(t)
+	m := &Manager{Root: t.TempDir()}
+	log := &reentrantLogger{m: m}
+	m.Logger = log
+	installAll(t, m, "1.22.5")
+	if log.calls == 0 {
+		t.Fatal("Install logged nothing")
+	}
+	if log.err != nil {
+		t.Fatal(log.err)
+	}
+}
+
+func TestDoctorFixUsesManagerRoot(t *testing.T) {
+	testEnv(t)
+	m := &Manager{Root: filepath.Join(t.TempDir(), "gvm")}
+	checks, err := m.Doctor(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(checks) != 1 || !checks[0].CanFix() {
+		t.Fatalf("Doctor on a missing root = %+v, want one fixable check", checks)
+	}
+	if err := checks[0].Fix(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(m.Root, "shims")); err != nil {
+		t.Fatalf("Fix did not initialize %s: %v", m.Root, err)
+	}
+}
+
+func TestManagersShareRoot(t *testing.T) {
+	testEnv(t)
+	root := t.TempDir()
+	versions := []string{"1.21.13", "1.22.5", "1.23.1"}
+	archives := make([]string, len(versions))
+	for i, v := range versions {
+		archives[i] = goArchive(t, v)
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(versions))
+	for i := range versions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m := &Manager{Root: root}
+			if _, errs[i] = m.InstallFile(context.Background(), archives[i], ""); errs[i] == nil {
+				_, errs[i] = m.SetAlias(context.Background(), "v"+versions[i], versions[i])
+			}
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("%s: %v", versions[i], err)
+		}
+	}
+
+	m := &Manager{Root: root}
+	list, err := m.List(context.Background())
+	if err != nil || len(list) != len(versions) {
+		t.Fatalf("List = %d versions, %v; want %d", len(list), err, len(versions))
+	}
+	// aliases.json is rewritten under the lock, so no update is lost
+	if aliases, err := m.Aliases(context.Background()); err != nil || len(aliases) != len(versions) {
+		t.Fatalf("Aliases = %v, %v", aliases, err)
+	}
+}
@@ -0,0 +1,57 @@
+package gvm
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/ibreez3/gvm/internal/core"
+)
+
+// Logger receives status messages such as "Extracting...". Infof is used for
+// progress, Warnf for problems that do not stop the operation.
+type Logger = core.Logger
+
+// StdLogger prints status messages to stdout and warnings to stderr, like the gvm command
+type StdLogger = core.StdLogger
+
+// NewJSONLogger returns a Logger writing one {"event":"message"} object per line to w
+func NewJSONLogger(w io.Writer) Logger {
+	return core.NewJSONLogger(w)
+}
+
+// Progress receives download progress. Start is called once data arrives,
+// Update with the bytes downloaded so far and Done when the download ends.
+type Progress = core.Progress
+
+// AutoProgress returns a progress bar if f is a terminal and periodic lines
+// otherwise. The bar is colored unless NO_COLOR is set.
+func AutoProgress(f *os.File) Progress {
+	return core.AutoProgress(f)
+}
+
+// NewBarProgress returns a progress bar redrawn in place on w
+func NewBarProgress(w io.Writer, color bool) Progress {
+	return core.NewBarProgress(w, color)
+}
+
+// NewLineProgress returns a progress reporter printing a line to w every interval
+func NewLineProgress(w io.Writer, interval time.Duration) Progress {
+	return core.NewLineProgress(w, interval)
+}
+
+// NewJSONProgress returns a progress reporter writing NDJSON events to w:
+// {"event":"download_start"}, {"event":"download","bytes":...} at most every
+// interval and {"event":"download_done"}
+func NewJSONProgress(w io.Writer, interval time.Duration) Progress {
+	return core.NewJSONProgress(w, interval)
+}
+
+// QuietLogger discards status messages and prints warnings to stderr, like
+// gvm --quiet
+type QuietLogger = core.QuietLogger
+
+// IsTerminal reports whether f is a terminal
+func IsTerminal(f *os.File) bool {
+	return core.IsTerminal(f)
+}